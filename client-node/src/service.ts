@@ -7,17 +7,27 @@ import * as pb_1 from "google-protobuf";
 import * as grpc_1 from "@grpc/grpc-js";
 export namespace proxy {
     export class ProxyRequest extends pb_1.Message {
-        #one_of_decls: number[][] = [[4]];
+        #one_of_decls: number[][] = [[4], [6], [8], [10], [11]];
         constructor(data?: any[] | ({
             url?: string;
             method?: string;
             headers?: Map<string, string>;
             retry_on_codes?: number[];
+            proxy_tags?: string[];
+            proxy_countries?: string[];
         } & (({
             priority?: number;
+        }) | ({
+            body?: Uint8Array;
+        }) | ({
+            prefer_proxy_tags?: boolean;
+        }) | ({
+            session_id?: string;
+        }) | ({
+            disable_cookie_jar?: boolean;
         })))) {
             super();
-            pb_1.Message.initialize(this, Array.isArray(data) ? data : [], 0, -1, [5], this.#one_of_decls);
+            pb_1.Message.initialize(this, Array.isArray(data) ? data : [], 0, -1, [5, 7, 9], this.#one_of_decls);
             if (!Array.isArray(data) && typeof data == "object") {
                 if ("url" in data && data.url != undefined) {
                     this.url = data.url;
@@ -34,6 +44,24 @@ export namespace proxy {
                 if ("retry_on_codes" in data && data.retry_on_codes != undefined) {
                     this.retry_on_codes = data.retry_on_codes;
                 }
+                if ("body" in data && data.body != undefined) {
+                    this.body = data.body;
+                }
+                if ("proxy_tags" in data && data.proxy_tags != undefined) {
+                    this.proxy_tags = data.proxy_tags;
+                }
+                if ("prefer_proxy_tags" in data && data.prefer_proxy_tags != undefined) {
+                    this.prefer_proxy_tags = data.prefer_proxy_tags;
+                }
+                if ("proxy_countries" in data && data.proxy_countries != undefined) {
+                    this.proxy_countries = data.proxy_countries;
+                }
+                if ("session_id" in data && data.session_id != undefined) {
+                    this.session_id = data.session_id;
+                }
+                if ("disable_cookie_jar" in data && data.disable_cookie_jar != undefined) {
+                    this.disable_cookie_jar = data.disable_cookie_jar;
+                }
             }
             if (!this.headers)
                 this.headers = new Map();
@@ -71,6 +99,54 @@ export namespace proxy {
         set retry_on_codes(value: number[]) {
             pb_1.Message.setField(this, 5, value);
         }
+        get body() {
+            return pb_1.Message.getFieldWithDefault(this, 6, new Uint8Array(0)) as Uint8Array;
+        }
+        set body(value: Uint8Array) {
+            pb_1.Message.setOneofField(this, 6, this.#one_of_decls[1], value);
+        }
+        get has_body() {
+            return pb_1.Message.getField(this, 6) != null;
+        }
+        get proxy_tags() {
+            return pb_1.Message.getFieldWithDefault(this, 7, []) as string[];
+        }
+        set proxy_tags(value: string[]) {
+            pb_1.Message.setField(this, 7, value);
+        }
+        get prefer_proxy_tags() {
+            return pb_1.Message.getFieldWithDefault(this, 8, false) as boolean;
+        }
+        set prefer_proxy_tags(value: boolean) {
+            pb_1.Message.setOneofField(this, 8, this.#one_of_decls[2], value);
+        }
+        get has_prefer_proxy_tags() {
+            return pb_1.Message.getField(this, 8) != null;
+        }
+        get proxy_countries() {
+            return pb_1.Message.getFieldWithDefault(this, 9, []) as string[];
+        }
+        set proxy_countries(value: string[]) {
+            pb_1.Message.setField(this, 9, value);
+        }
+        get session_id() {
+            return pb_1.Message.getFieldWithDefault(this, 10, "") as string;
+        }
+        set session_id(value: string) {
+            pb_1.Message.setOneofField(this, 10, this.#one_of_decls[3], value);
+        }
+        get has_session_id() {
+            return pb_1.Message.getField(this, 10) != null;
+        }
+        get disable_cookie_jar() {
+            return pb_1.Message.getFieldWithDefault(this, 11, false) as boolean;
+        }
+        set disable_cookie_jar(value: boolean) {
+            pb_1.Message.setOneofField(this, 11, this.#one_of_decls[4], value);
+        }
+        get has_disable_cookie_jar() {
+            return pb_1.Message.getField(this, 11) != null;
+        }
         get _priority() {
             const cases: {
                 [index: number]: "none" | "priority";
@@ -80,6 +156,42 @@ export namespace proxy {
             };
             return cases[pb_1.Message.computeOneofCase(this, [4])];
         }
+        get _body() {
+            const cases: {
+                [index: number]: "none" | "body";
+            } = {
+                0: "none",
+                6: "body"
+            };
+            return cases[pb_1.Message.computeOneofCase(this, [6])];
+        }
+        get _prefer_proxy_tags() {
+            const cases: {
+                [index: number]: "none" | "prefer_proxy_tags";
+            } = {
+                0: "none",
+                8: "prefer_proxy_tags"
+            };
+            return cases[pb_1.Message.computeOneofCase(this, [8])];
+        }
+        get _session_id() {
+            const cases: {
+                [index: number]: "none" | "session_id";
+            } = {
+                0: "none",
+                10: "session_id"
+            };
+            return cases[pb_1.Message.computeOneofCase(this, [10])];
+        }
+        get _disable_cookie_jar() {
+            const cases: {
+                [index: number]: "none" | "disable_cookie_jar";
+            } = {
+                0: "none",
+                11: "disable_cookie_jar"
+            };
+            return cases[pb_1.Message.computeOneofCase(this, [11])];
+        }
         static fromObject(data: {
             url?: string;
             method?: string;
@@ -88,6 +200,12 @@ export namespace proxy {
             };
             priority?: number;
             retry_on_codes?: number[];
+            body?: Uint8Array;
+            proxy_tags?: string[];
+            prefer_proxy_tags?: boolean;
+            proxy_countries?: string[];
+            session_id?: string;
+            disable_cookie_jar?: boolean;
         }): ProxyRequest {
             const message = new ProxyRequest({});
             if (data.url != null) {
@@ -105,6 +223,24 @@ export namespace proxy {
             if (data.retry_on_codes != null) {
                 message.retry_on_codes = data.retry_on_codes;
             }
+            if (data.body != null) {
+                message.body = data.body;
+            }
+            if (data.proxy_tags != null) {
+                message.proxy_tags = data.proxy_tags;
+            }
+            if (data.prefer_proxy_tags != null) {
+                message.prefer_proxy_tags = data.prefer_proxy_tags;
+            }
+            if (data.proxy_countries != null) {
+                message.proxy_countries = data.proxy_countries;
+            }
+            if (data.session_id != null) {
+                message.session_id = data.session_id;
+            }
+            if (data.disable_cookie_jar != null) {
+                message.disable_cookie_jar = data.disable_cookie_jar;
+            }
             return message;
         }
         toObject() {
@@ -116,6 +252,12 @@ export namespace proxy {
                 };
                 priority?: number;
                 retry_on_codes?: number[];
+                body?: Uint8Array;
+                proxy_tags?: string[];
+                prefer_proxy_tags?: boolean;
+                proxy_countries?: string[];
+                session_id?: string;
+                disable_cookie_jar?: boolean;
             } = {};
             if (this.url != null) {
                 data.url = this.url;
@@ -132,6 +274,24 @@ export namespace proxy {
             if (this.retry_on_codes != null) {
                 data.retry_on_codes = this.retry_on_codes;
             }
+            if (this.body != null) {
+                data.body = this.body;
+            }
+            if (this.proxy_tags != null) {
+                data.proxy_tags = this.proxy_tags;
+            }
+            if (this.prefer_proxy_tags != null) {
+                data.prefer_proxy_tags = this.prefer_proxy_tags;
+            }
+            if (this.proxy_countries != null) {
+                data.proxy_countries = this.proxy_countries;
+            }
+            if (this.session_id != null) {
+                data.session_id = this.session_id;
+            }
+            if (this.disable_cookie_jar != null) {
+                data.disable_cookie_jar = this.disable_cookie_jar;
+            }
             return data;
         }
         serialize(): Uint8Array;
@@ -152,6 +312,18 @@ export namespace proxy {
                 writer.writeInt64(4, this.priority);
             if (this.retry_on_codes.length)
                 writer.writePackedUint32(5, this.retry_on_codes);
+            if (this.has_body)
+                writer.writeBytes(6, this.body);
+            if (this.proxy_tags.length)
+                writer.writeRepeatedString(7, this.proxy_tags);
+            if (this.has_prefer_proxy_tags)
+                writer.writeBool(8, this.prefer_proxy_tags);
+            if (this.proxy_countries.length)
+                writer.writeRepeatedString(9, this.proxy_countries);
+            if (this.has_session_id)
+                writer.writeString(10, this.session_id);
+            if (this.has_disable_cookie_jar)
+                writer.writeBool(11, this.disable_cookie_jar);
             if (!w)
                 return writer.getResultBuffer();
         }
@@ -176,6 +348,24 @@ export namespace proxy {
                     case 5:
                         message.retry_on_codes = reader.readPackedUint32();
                         break;
+                    case 6:
+                        message.body = reader.readBytes();
+                        break;
+                    case 7:
+                        pb_1.Message.addToRepeatedField(message, 7, reader.readString());
+                        break;
+                    case 8:
+                        message.prefer_proxy_tags = reader.readBool();
+                        break;
+                    case 9:
+                        pb_1.Message.addToRepeatedField(message, 9, reader.readString());
+                        break;
+                    case 10:
+                        message.session_id = reader.readString();
+                        break;
+                    case 11:
+                        message.disable_cookie_jar = reader.readBool();
+                        break;
                     default: reader.skipField();
                 }
             }
@@ -188,11 +378,80 @@ export namespace proxy {
             return ProxyRequest.deserialize(bytes);
         }
     }
+    export class HeaderValues extends pb_1.Message {
+        #one_of_decls: number[][] = [];
+        constructor(data?: any[] | ({
+            values?: string[];
+        }) {
+            super();
+            pb_1.Message.initialize(this, Array.isArray(data) ? data : [], 0, -1, [1], this.#one_of_decls);
+            if (!Array.isArray(data) && typeof data == "object") {
+                if ("values" in data && data.values != undefined) {
+                    this.values = data.values;
+                }
+            }
+        }
+        get values() {
+            return pb_1.Message.getFieldWithDefault(this, 1, []) as string[];
+        }
+        set values(value: string[]) {
+            pb_1.Message.setField(this, 1, value);
+        }
+        static fromObject(data: {
+            values?: string[];
+        }): HeaderValues {
+            const message = new HeaderValues({});
+            if (data.values != null) {
+                message.values = data.values;
+            }
+            return message;
+        }
+        toObject() {
+            const data: {
+                values?: string[];
+            } = {};
+            if (this.values != null) {
+                data.values = this.values;
+            }
+            return data;
+        }
+        serialize(): Uint8Array;
+        serialize(w: pb_1.BinaryWriter): void;
+        serialize(w?: pb_1.BinaryWriter): Uint8Array | void {
+            const writer = w || new pb_1.BinaryWriter();
+            if (this.values.length)
+                writer.writeRepeatedString(1, this.values);
+            if (!w)
+                return writer.getResultBuffer();
+        }
+        static deserialize(bytes: Uint8Array | pb_1.BinaryReader): HeaderValues {
+            const reader = bytes instanceof pb_1.BinaryReader ? bytes : new pb_1.BinaryReader(bytes), message = new HeaderValues();
+            while (reader.nextField()) {
+                if (reader.isEndGroup())
+                    break;
+                switch (reader.getFieldNumber()) {
+                    case 1:
+                        pb_1.Message.addToRepeatedField(message, 1, reader.readString());
+                        break;
+                    default: reader.skipField();
+                }
+            }
+            return message;
+        }
+        serializeBinary(): Uint8Array {
+            return this.serialize();
+        }
+        static deserializeBinary(bytes: Uint8Array): HeaderValues {
+            return HeaderValues.deserialize(bytes);
+        }
+    }
     export class ProxyResponseSuccess extends pb_1.Message {
         #one_of_decls: number[][] = [[3]];
         constructor(data?: any[] | ({
             status?: number;
             headers?: Map<string, string>;
+            header_values?: Map<string, HeaderValues>;
+            session_changed?: boolean;
         } & (({
             body?: Uint8Array;
         })))) {
@@ -208,9 +467,17 @@ export namespace proxy {
                 if ("body" in data && data.body != undefined) {
                     this.body = data.body;
                 }
+                if ("header_values" in data && data.header_values != undefined) {
+                    this.header_values = data.header_values;
+                }
+                if ("session_changed" in data && data.session_changed != undefined) {
+                    this.session_changed = data.session_changed;
+                }
             }
             if (!this.headers)
                 this.headers = new Map();
+            if (!this.header_values)
+                this.header_values = new Map();
         }
         get status() {
             return pb_1.Message.getFieldWithDefault(this, 1, 0) as number;
@@ -233,6 +500,18 @@ export namespace proxy {
         get has_body() {
             return pb_1.Message.getField(this, 3) != null;
         }
+        get header_values() {
+            return pb_1.Message.getField(this, 4) as any as Map<string, HeaderValues>;
+        }
+        set header_values(value: Map<string, HeaderValues>) {
+            pb_1.Message.setField(this, 4, value as any);
+        }
+        get session_changed() {
+            return pb_1.Message.getFieldWithDefault(this, 5, false) as boolean;
+        }
+        set session_changed(value: boolean) {
+            pb_1.Message.setField(this, 5, value);
+        }
         get _body() {
             const cases: {
                 [index: number]: "none" | "body";
@@ -248,6 +527,10 @@ export namespace proxy {
                 [key: string]: string;
             };
             body?: Uint8Array;
+            header_values?: {
+                [key: string]: ReturnType<typeof HeaderValues.prototype.toObject>;
+            };
+            session_changed?: boolean;
         }): ProxyResponseSuccess {
             const message = new ProxyResponseSuccess({});
             if (data.status != null) {
@@ -259,6 +542,12 @@ export namespace proxy {
             if (data.body != null) {
                 message.body = data.body;
             }
+            if (typeof data.header_values == "object") {
+                message.header_values = new Map(Object.entries(data.header_values).map(([key, value]) => [key, HeaderValues.fromObject(value)]));
+            }
+            if (data.session_changed != null) {
+                message.session_changed = data.session_changed;
+            }
             return message;
         }
         toObject() {
@@ -268,6 +557,10 @@ export namespace proxy {
                     [key: string]: string;
                 };
                 body?: Uint8Array;
+                header_values?: {
+                    [key: string]: ReturnType<typeof HeaderValues.prototype.toObject>;
+                };
+                session_changed?: boolean;
             } = {};
             if (this.status != null) {
                 data.status = this.status;
@@ -278,6 +571,12 @@ export namespace proxy {
             if (this.body != null) {
                 data.body = this.body;
             }
+            if (this.header_values != null) {
+                data.header_values = (Object.fromEntries)((Array.from)(this.header_values).map(([key, value]) => [key, value.toObject()]));
+            }
+            if (this.session_changed != null) {
+                data.session_changed = this.session_changed;
+            }
             return data;
         }
         serialize(): Uint8Array;
@@ -294,6 +593,14 @@ export namespace proxy {
             }
             if (this.has_body)
                 writer.writeBytes(3, this.body);
+            for (const [key, value] of this.header_values) {
+                writer.writeMessage(4, this.header_values, () => {
+                    writer.writeString(1, key);
+                    writer.writeMessage(2, value, () => value.serialize(writer));
+                });
+            }
+            if (this.session_changed != false)
+                writer.writeBool(5, this.session_changed);
             if (!w)
                 return writer.getResultBuffer();
         }
@@ -312,6 +619,16 @@ export namespace proxy {
                     case 3:
                         message.body = reader.readBytes();
                         break;
+                    case 4:
+                        reader.readMessage(message, () => pb_1.Map.deserializeBinary(message.header_values as any, reader, reader.readString, () => {
+                            let value;
+                            reader.readMessage(message, () => value = HeaderValues.deserialize(reader));
+                            return value;
+                        }));
+                        break;
+                    case 5:
+                        message.session_changed = reader.readBool();
+                        break;
                     default: reader.skipField();
                 }
             }
@@ -433,7 +750,8 @@ export namespace proxy {
             INVALID_URL = 1,
             PROXY_ERROR = 2,
             REMOTE_HOST_TIMED_OUT = 3,
-            REMOTE_HOST_UNREACHABLE = 4
+            REMOTE_HOST_UNREACHABLE = 4,
+            INVALID_METHOD = 5
         }
     }
     export class ProxyResponse extends pb_1.Message {
@@ -545,6 +863,274 @@ export namespace proxy {
             return ProxyResponse.deserialize(bytes);
         }
     }
+    export class ProxyResponseHead extends pb_1.Message {
+        #one_of_decls: number[][] = [];
+        constructor(data?: any[] | ({
+            status?: number;
+            header_values?: Map<string, HeaderValues>;
+            session_changed?: boolean;
+        }) {
+            super();
+            pb_1.Message.initialize(this, Array.isArray(data) ? data : [], 0, -1, [], this.#one_of_decls);
+            if (!Array.isArray(data) && typeof data == "object") {
+                if ("status" in data && data.status != undefined) {
+                    this.status = data.status;
+                }
+                if ("header_values" in data && data.header_values != undefined) {
+                    this.header_values = data.header_values;
+                }
+                if ("session_changed" in data && data.session_changed != undefined) {
+                    this.session_changed = data.session_changed;
+                }
+            }
+            if (!this.header_values)
+                this.header_values = new Map();
+        }
+        get status() {
+            return pb_1.Message.getFieldWithDefault(this, 1, 0) as number;
+        }
+        set status(value: number) {
+            pb_1.Message.setField(this, 1, value);
+        }
+        get header_values() {
+            return pb_1.Message.getField(this, 2) as any as Map<string, HeaderValues>;
+        }
+        set header_values(value: Map<string, HeaderValues>) {
+            pb_1.Message.setField(this, 2, value as any);
+        }
+        get session_changed() {
+            return pb_1.Message.getFieldWithDefault(this, 3, false) as boolean;
+        }
+        set session_changed(value: boolean) {
+            pb_1.Message.setField(this, 3, value);
+        }
+        static fromObject(data: {
+            status?: number;
+            header_values?: {
+                [key: string]: ReturnType<typeof HeaderValues.prototype.toObject>;
+            };
+            session_changed?: boolean;
+        }): ProxyResponseHead {
+            const message = new ProxyResponseHead({});
+            if (data.status != null) {
+                message.status = data.status;
+            }
+            if (typeof data.header_values == "object") {
+                message.header_values = new Map(Object.entries(data.header_values).map(([key, value]) => [key, HeaderValues.fromObject(value)]));
+            }
+            if (data.session_changed != null) {
+                message.session_changed = data.session_changed;
+            }
+            return message;
+        }
+        toObject() {
+            const data: {
+                status?: number;
+                header_values?: {
+                    [key: string]: ReturnType<typeof HeaderValues.prototype.toObject>;
+                };
+                session_changed?: boolean;
+            } = {};
+            if (this.status != null) {
+                data.status = this.status;
+            }
+            if (this.header_values != null) {
+                data.header_values = (Object.fromEntries)((Array.from)(this.header_values).map(([key, value]) => [key, value.toObject()]));
+            }
+            if (this.session_changed != null) {
+                data.session_changed = this.session_changed;
+            }
+            return data;
+        }
+        serialize(): Uint8Array;
+        serialize(w: pb_1.BinaryWriter): void;
+        serialize(w?: pb_1.BinaryWriter): Uint8Array | void {
+            const writer = w || new pb_1.BinaryWriter();
+            if (this.status != 0)
+                writer.writeInt32(1, this.status);
+            for (const [key, value] of this.header_values) {
+                writer.writeMessage(2, this.header_values, () => {
+                    writer.writeString(1, key);
+                    writer.writeMessage(2, value, () => value.serialize(writer));
+                });
+            }
+            if (this.session_changed != false)
+                writer.writeBool(3, this.session_changed);
+            if (!w)
+                return writer.getResultBuffer();
+        }
+        static deserialize(bytes: Uint8Array | pb_1.BinaryReader): ProxyResponseHead {
+            const reader = bytes instanceof pb_1.BinaryReader ? bytes : new pb_1.BinaryReader(bytes), message = new ProxyResponseHead();
+            while (reader.nextField()) {
+                if (reader.isEndGroup())
+                    break;
+                switch (reader.getFieldNumber()) {
+                    case 1:
+                        message.status = reader.readInt32();
+                        break;
+                    case 2:
+                        reader.readMessage(message, () => pb_1.Map.deserializeBinary(message.header_values as any, reader, reader.readString, () => {
+                            let value;
+                            reader.readMessage(message, () => value = HeaderValues.deserialize(reader));
+                            return value;
+                        }));
+                        break;
+                    case 3:
+                        message.session_changed = reader.readBool();
+                        break;
+                    default: reader.skipField();
+                }
+            }
+            return message;
+        }
+        serializeBinary(): Uint8Array {
+            return this.serialize();
+        }
+        static deserializeBinary(bytes: Uint8Array): ProxyResponseHead {
+            return ProxyResponseHead.deserialize(bytes);
+        }
+    }
+    export class ProxyResponseChunk extends pb_1.Message {
+        #one_of_decls: number[][] = [[1, 2, 3]];
+        constructor(data?: any[] | ({} & (({
+            head?: ProxyResponseHead;
+            body?: never;
+            error?: never;
+        } | {
+            head?: never;
+            body?: Uint8Array;
+            error?: never;
+        } | {
+            head?: never;
+            body?: never;
+            error?: ProxyResponseError;
+        })))) {
+            super();
+            pb_1.Message.initialize(this, Array.isArray(data) ? data : [], 0, -1, [], this.#one_of_decls);
+            if (!Array.isArray(data) && typeof data == "object") {
+                if ("head" in data && data.head != undefined) {
+                    this.head = data.head;
+                }
+                if ("body" in data && data.body != undefined) {
+                    this.body = data.body;
+                }
+                if ("error" in data && data.error != undefined) {
+                    this.error = data.error;
+                }
+            }
+        }
+        get head() {
+            return pb_1.Message.getWrapperField(this, ProxyResponseHead, 1) as ProxyResponseHead;
+        }
+        set head(value: ProxyResponseHead) {
+            pb_1.Message.setOneofWrapperField(this, 1, this.#one_of_decls[0], value);
+        }
+        get has_head() {
+            return pb_1.Message.getField(this, 1) != null;
+        }
+        get body() {
+            return pb_1.Message.getFieldWithDefault(this, 2, new Uint8Array(0)) as Uint8Array;
+        }
+        set body(value: Uint8Array) {
+            pb_1.Message.setOneofField(this, 2, this.#one_of_decls[0], value);
+        }
+        get has_body() {
+            return pb_1.Message.getField(this, 2) != null;
+        }
+        get error() {
+            return pb_1.Message.getWrapperField(this, ProxyResponseError, 3) as ProxyResponseError;
+        }
+        set error(value: ProxyResponseError) {
+            pb_1.Message.setOneofWrapperField(this, 3, this.#one_of_decls[0], value);
+        }
+        get has_error() {
+            return pb_1.Message.getField(this, 3) != null;
+        }
+        get chunk() {
+            const cases: {
+                [index: number]: "none" | "head" | "body" | "error";
+            } = {
+                0: "none",
+                1: "head",
+                2: "body",
+                3: "error"
+            };
+            return cases[pb_1.Message.computeOneofCase(this, [1, 2, 3])];
+        }
+        static fromObject(data: {
+            head?: ReturnType<typeof ProxyResponseHead.prototype.toObject>;
+            body?: Uint8Array;
+            error?: ReturnType<typeof ProxyResponseError.prototype.toObject>;
+        }): ProxyResponseChunk {
+            const message = new ProxyResponseChunk({});
+            if (data.head != null) {
+                message.head = ProxyResponseHead.fromObject(data.head);
+            }
+            if (data.body != null) {
+                message.body = data.body;
+            }
+            if (data.error != null) {
+                message.error = ProxyResponseError.fromObject(data.error);
+            }
+            return message;
+        }
+        toObject() {
+            const data: {
+                head?: ReturnType<typeof ProxyResponseHead.prototype.toObject>;
+                body?: Uint8Array;
+                error?: ReturnType<typeof ProxyResponseError.prototype.toObject>;
+            } = {};
+            if (this.head != null) {
+                data.head = this.head.toObject();
+            }
+            if (this.body != null) {
+                data.body = this.body;
+            }
+            if (this.error != null) {
+                data.error = this.error.toObject();
+            }
+            return data;
+        }
+        serialize(): Uint8Array;
+        serialize(w: pb_1.BinaryWriter): void;
+        serialize(w?: pb_1.BinaryWriter): Uint8Array | void {
+            const writer = w || new pb_1.BinaryWriter();
+            if (this.has_head)
+                writer.writeMessage(1, this.head, () => this.head.serialize(writer));
+            if (this.has_body)
+                writer.writeBytes(2, this.body);
+            if (this.has_error)
+                writer.writeMessage(3, this.error, () => this.error.serialize(writer));
+            if (!w)
+                return writer.getResultBuffer();
+        }
+        static deserialize(bytes: Uint8Array | pb_1.BinaryReader): ProxyResponseChunk {
+            const reader = bytes instanceof pb_1.BinaryReader ? bytes : new pb_1.BinaryReader(bytes), message = new ProxyResponseChunk();
+            while (reader.nextField()) {
+                if (reader.isEndGroup())
+                    break;
+                switch (reader.getFieldNumber()) {
+                    case 1:
+                        reader.readMessage(message.head, () => message.head = ProxyResponseHead.deserialize(reader));
+                        break;
+                    case 2:
+                        message.body = reader.readBytes();
+                        break;
+                    case 3:
+                        reader.readMessage(message.error, () => message.error = ProxyResponseError.deserialize(reader));
+                        break;
+                    default: reader.skipField();
+                }
+            }
+            return message;
+        }
+        serializeBinary(): Uint8Array {
+            return this.serialize();
+        }
+        static deserializeBinary(bytes: Uint8Array): ProxyResponseChunk {
+            return ProxyResponseChunk.deserialize(bytes);
+        }
+    }
     interface GrpcUnaryServiceInterface<P, R> {
         (message: P, metadata: grpc_1.Metadata, options: grpc_1.CallOptions, callback: grpc_1.requestCallback<R>): grpc_1.ClientUnaryCall;
         (message: P, metadata: grpc_1.Metadata, callback: grpc_1.requestCallback<R>): grpc_1.ClientUnaryCall;
@@ -579,10 +1165,20 @@ export namespace proxy {
                 requestDeserialize: (bytes: Buffer) => ProxyRequest.deserialize(new Uint8Array(bytes)),
                 responseSerialize: (message: ProxyResponse) => Buffer.from(message.serialize()),
                 responseDeserialize: (bytes: Buffer) => ProxyResponse.deserialize(new Uint8Array(bytes))
+            },
+            StreamRequest: {
+                path: "/proxy.Proxy/StreamRequest",
+                requestStream: false,
+                responseStream: true,
+                requestSerialize: (message: ProxyRequest) => Buffer.from(message.serialize()),
+                requestDeserialize: (bytes: Buffer) => ProxyRequest.deserialize(new Uint8Array(bytes)),
+                responseSerialize: (message: ProxyResponseChunk) => Buffer.from(message.serialize()),
+                responseDeserialize: (bytes: Buffer) => ProxyResponseChunk.deserialize(new Uint8Array(bytes))
             }
         };
         [method: string]: grpc_1.UntypedHandleCall;
         abstract SendRequest(call: grpc_1.ServerUnaryCall<ProxyRequest, ProxyResponse>, callback: grpc_1.sendUnaryData<ProxyResponse>): void;
+        abstract StreamRequest(call: grpc_1.ServerWritableStream<ProxyRequest, ProxyResponseChunk>): void;
     }
     export class ProxyClient extends grpc_1.makeGenericClientConstructor(UnimplementedProxyService.definition, "Proxy", {}) {
         constructor(address: string, credentials: grpc_1.ChannelCredentials, options?: Partial<grpc_1.ChannelOptions>) {
@@ -591,5 +1187,8 @@ export namespace proxy {
         SendRequest: GrpcUnaryServiceInterface<ProxyRequest, ProxyResponse> = (message: ProxyRequest, metadata: grpc_1.Metadata | grpc_1.CallOptions | grpc_1.requestCallback<ProxyResponse>, options?: grpc_1.CallOptions | grpc_1.requestCallback<ProxyResponse>, callback?: grpc_1.requestCallback<ProxyResponse>): grpc_1.ClientUnaryCall => {
             return super.SendRequest(message, metadata, options, callback);
         };
+        StreamRequest: GrpcStreamServiceInterface<ProxyRequest, ProxyResponseChunk> = (message: ProxyRequest, metadata?: grpc_1.Metadata | grpc_1.CallOptions, options?: grpc_1.CallOptions): grpc_1.ClientReadableStream<ProxyResponseChunk> => {
+            return super.StreamRequest(message, metadata, options);
+        };
     }
 }
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/inhies/go-bytesize"
+)
+
+// BandwidthUsage counts requests and bytes sent and received, in total and in the current day and month (UTC) for quotas
+type BandwidthUsage struct {
+	lock     sync.Mutex
+	requests int64
+	failed   int64
+	in       int64
+	out      int64
+	day      string
+	dayBytes int64
+	// bytes in the current month
+	month      string
+	monthBytes int64
+}
+
+func (usage *BandwidthUsage) add(in int64, out int64, failed bool, now time.Time) {
+	usage.lock.Lock()
+	defer usage.lock.Unlock()
+
+	usage.roll(now)
+	usage.requests++
+	if failed {
+		usage.failed++
+	}
+	usage.in += in
+	usage.out += out
+	usage.dayBytes += in + out
+	usage.monthBytes += in + out
+}
+
+// roll resets the period counters when a new day or month started, usage lock must be held
+func (usage *BandwidthUsage) roll(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if usage.day != day {
+		usage.day = day
+		usage.dayBytes = 0
+	}
+
+	month := now.UTC().Format("2006-01")
+	if usage.month != month {
+		usage.month = month
+		usage.monthBytes = 0
+	}
+}
+
+// exceeds reports whether the usage of the current day or month reached one of the quotas, zero quotas are unlimited
+func (usage *BandwidthUsage) exceeds(daily bytesize.ByteSize, monthly bytesize.ByteSize, now time.Time) bool {
+	usage.lock.Lock()
+	defer usage.lock.Unlock()
+
+	usage.roll(now)
+
+	return (daily > 0 && float64(usage.dayBytes) >= float64(daily)) || (monthly > 0 && float64(usage.monthBytes) >= float64(monthly))
+}
+
+// BandwidthView is what the dashboard shows about bandwidth of a proxy, host or source
+type BandwidthView struct {
+	Name     string
+	Requests int64
+	Failed   int64
+	In       string
+	Out      string
+	Today    string
+	Month    string
+	total    int64
+}
+
+func (usage *BandwidthUsage) view(name string) BandwidthView {
+	usage.lock.Lock()
+	defer usage.lock.Unlock()
+
+	usage.roll(time.Now())
+
+	return BandwidthView{
+		Name:     name,
+		Requests: usage.requests,
+		Failed:   usage.failed,
+		In:       bytesize.New(float64(usage.in)).String(),
+		Out:      bytesize.New(float64(usage.out)).String(),
+		Today:    bytesize.New(float64(usage.dayBytes)).String(),
+		Month:    bytesize.New(float64(usage.monthBytes)).String(),
+		total:    usage.in + usage.out,
+	}
+}
+
+// BandwidthAccounting holds bandwidth per target host and per proxy source, usage per proxy is kept by the ProxyClient
+type BandwidthAccounting struct {
+	lock    sync.Mutex
+	hosts   map[string]*BandwidthUsage
+	sources map[string]*BandwidthUsage
+}
+
+var bandwidth = &BandwidthAccounting{
+	hosts:   make(map[string]*BandwidthUsage),
+	sources: make(map[string]*BandwidthUsage),
+}
+
+func usageOf(usages map[string]*BandwidthUsage, name string) *BandwidthUsage {
+	usage, ok := usages[name]
+	if !ok {
+		usage = &BandwidthUsage{}
+		usages[name] = usage
+	}
+
+	return usage
+}
+
+// source returns usage of the proxy source, it is shared by all proxies of the source
+func (accounting *BandwidthAccounting) source(name string) *BandwidthUsage {
+	accounting.lock.Lock()
+	defer accounting.lock.Unlock()
+
+	return usageOf(accounting.sources, name)
+}
+
+func (accounting *BandwidthAccounting) host(name string) *BandwidthUsage {
+	accounting.lock.Lock()
+	defer accounting.lock.Unlock()
+
+	return usageOf(accounting.hosts, name)
+}
+
+// views returns usage of hosts or sources sorted by total bytes
+func (accounting *BandwidthAccounting) views(usages map[string]*BandwidthUsage) []BandwidthView {
+	accounting.lock.Lock()
+	copied := make(map[string]*BandwidthUsage, len(usages))
+	for name, usage := range usages {
+		copied[name] = usage
+	}
+	accounting.lock.Unlock()
+
+	views := make([]BandwidthView, 0, len(copied))
+	for name, usage := range copied {
+		views = append(views, usage.view(name))
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].total > views[j].total
+	})
+
+	return views
+}
+
+// headerSize approximates the size of headers on the wire
+func headerSize(header http.Header) int64 {
+	size := int64(0)
+	for key, values := range header {
+		for _, value := range values {
+			size += int64(len(key) + len(value) + 4)
+		}
+	}
+
+	return size
+}
+
+// requestSize approximates the size of a request on the wire
+func requestSize(request *http.Request, body []byte) int64 {
+	return int64(len(request.Method)+len(request.URL.String())+12) + headerSize(request.Header) + int64(len(body))
+}
+
+// responseSize approximates the size of a response on the wire
+func responseSize(resp *http.Response, body int64) int64 {
+	return int64(len(resp.Status)+12) + headerSize(resp.Header) + body
+}
+
+// recordBandwidth adds a request made through the proxy and its bytes to the proxy, its source and the target host,
+// failed requests are recorded with what was sent and received before they failed
+func (client *ProxyClient) recordBandwidth(host string, in int64, out int64, failed bool) {
+	now := time.Now()
+
+	client.bandwidth.add(in, out, failed, now)
+	bandwidth.source(client.config.source).add(in, out, failed, now)
+	bandwidth.host(host).add(in, out, failed, now)
+
+	if !client.quotaExhausted.Load() && client.exceedsQuota(now) {
+		proxyPool.updateQuotas()
+	}
+}
+
+// exceedsQuota reports whether the proxy or its source used up a daily or monthly quota
+func (client *ProxyClient) exceedsQuota(now time.Time) bool {
+	if client.bandwidth.exceeds(globalConfiguration.ProxyDailyQuota, globalConfiguration.ProxyMonthlyQuota, now) {
+		return true
+	}
+
+	source := client.config.source
+
+	return bandwidth.source(source).exceeds(globalConfiguration.SourceDailyQuota[source], globalConfiguration.SourceMonthlyQuota[source], now)
+}
+
+// updateQuotas removes proxies that used up their quota from scheduling and adds them back when a new day or month starts
+func (pool *ProxyPool) updateQuotas() {
+	now := time.Now()
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	changed := false
+	for _, client := range pool.clients {
+		exceeded := client.exceedsQuota(now)
+		if client.quotaExhausted.Swap(exceeded) != exceeded {
+			changed = true
+
+			if exceeded {
+				log.Printf("Proxy %s used up its bandwidth quota, removing it from the pool", client.id())
+			} else {
+				log.Printf("Bandwidth quota of proxy %s renewed, adding it back to the pool", client.id())
+			}
+		}
+	}
+
+	if changed {
+		pool.publish()
+	}
+}
+
+// runQuotaChecker renews quotas of proxies once a new day or month starts
+func runQuotaChecker(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			proxyPool.updateQuotas()
+		}
+	}
+}
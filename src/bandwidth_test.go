@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/inhies/go-bytesize"
+)
+
+type bandwidthAdd struct {
+	at    string
+	bytes int64
+}
+
+func TestBandwidthUsageRoll(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return parsed
+	}
+
+	tests := []struct {
+		name string
+		// bytes received at the given times, in order
+		adds       []bandwidthAdd
+		now        string
+		total      int64
+		dayBytes   int64
+		monthBytes int64
+	}{
+		{
+			name:       "same day",
+			adds:       []bandwidthAdd{{"2026-03-10T08:00:00Z", 100}, {"2026-03-10T20:00:00Z", 50}},
+			now:        "2026-03-10T23:59:59Z",
+			total:      150,
+			dayBytes:   150,
+			monthBytes: 150,
+		},
+		{
+			name:       "next day",
+			adds:       []bandwidthAdd{{"2026-03-10T08:00:00Z", 100}, {"2026-03-11T00:00:00Z", 50}},
+			now:        "2026-03-11T01:00:00Z",
+			total:      150,
+			dayBytes:   50,
+			monthBytes: 150,
+		},
+		{
+			name:       "next month",
+			adds:       []bandwidthAdd{{"2026-03-31T23:00:00Z", 100}, {"2026-04-01T01:00:00Z", 50}},
+			now:        "2026-04-01T02:00:00Z",
+			total:      150,
+			dayBytes:   50,
+			monthBytes: 50,
+		},
+		{
+			name:       "days are UTC",
+			adds:       []bandwidthAdd{{"2026-03-10T23:00:00Z", 100}, {"2026-03-11T01:00:00+02:00", 50}},
+			now:        "2026-03-10T23:30:00Z",
+			total:      150,
+			dayBytes:   150,
+			monthBytes: 150,
+		},
+		{
+			name:       "nothing today",
+			adds:       []bandwidthAdd{{"2026-03-10T08:00:00Z", 100}},
+			now:        "2026-03-12T08:00:00Z",
+			total:      100,
+			dayBytes:   0,
+			monthBytes: 100,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			usage := &BandwidthUsage{}
+
+			for _, added := range test.adds {
+				usage.add(added.bytes, 0, false, at(added.at))
+			}
+
+			now := at(test.now)
+			usage.roll(now)
+
+			if usage.in != test.total || usage.dayBytes != test.dayBytes || usage.monthBytes != test.monthBytes {
+				t.Errorf("expected %d total, %d today and %d this month, got %d, %d and %d", test.total, test.dayBytes, test.monthBytes, usage.in, usage.dayBytes, usage.monthBytes)
+			}
+
+			if usage.requests != int64(len(test.adds)) {
+				t.Errorf("expected %d requests, got %d", len(test.adds), usage.requests)
+			}
+
+			if test.dayBytes > 0 && !usage.exceeds(bytesize.ByteSize(test.dayBytes), 0, now) {
+				t.Errorf("expected the daily quota of %d bytes to be reached", test.dayBytes)
+			}
+
+			if usage.exceeds(bytesize.ByteSize(test.dayBytes+1), bytesize.ByteSize(test.monthBytes+1), now) {
+				t.Errorf("expected quotas above the usage not to be reached")
+			}
+		})
+	}
+}
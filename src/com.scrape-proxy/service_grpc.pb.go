@@ -23,6 +23,8 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ProxyClient interface {
 	SendRequest(ctx context.Context, in *ProxyRequest, opts ...grpc.CallOption) (*ProxyResponse, error)
+	// sends a head chunk with status and headers followed by body chunks as they are downloaded
+	StreamRequest(ctx context.Context, in *ProxyRequest, opts ...grpc.CallOption) (Proxy_StreamRequestClient, error)
 }
 
 type proxyClient struct {
@@ -42,11 +44,45 @@ func (c *proxyClient) SendRequest(ctx context.Context, in *ProxyRequest, opts ..
 	return out, nil
 }
 
+func (c *proxyClient) StreamRequest(ctx context.Context, in *ProxyRequest, opts ...grpc.CallOption) (Proxy_StreamRequestClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Proxy_ServiceDesc.Streams[0], "/proxy.Proxy/StreamRequest", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &proxyStreamRequestClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Proxy_StreamRequestClient interface {
+	Recv() (*ProxyResponseChunk, error)
+	grpc.ClientStream
+}
+
+type proxyStreamRequestClient struct {
+	grpc.ClientStream
+}
+
+func (x *proxyStreamRequestClient) Recv() (*ProxyResponseChunk, error) {
+	m := new(ProxyResponseChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ProxyServer is the server API for Proxy service.
 // All implementations must embed UnimplementedProxyServer
 // for forward compatibility
 type ProxyServer interface {
 	SendRequest(context.Context, *ProxyRequest) (*ProxyResponse, error)
+	// sends a head chunk with status and headers followed by body chunks as they are downloaded
+	StreamRequest(*ProxyRequest, Proxy_StreamRequestServer) error
 	mustEmbedUnimplementedProxyServer()
 }
 
@@ -57,6 +93,9 @@ type UnimplementedProxyServer struct {
 func (UnimplementedProxyServer) SendRequest(context.Context, *ProxyRequest) (*ProxyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendRequest not implemented")
 }
+func (UnimplementedProxyServer) StreamRequest(*ProxyRequest, Proxy_StreamRequestServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRequest not implemented")
+}
 func (UnimplementedProxyServer) mustEmbedUnimplementedProxyServer() {}
 
 // UnsafeProxyServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +127,27 @@ func _Proxy_SendRequest_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Proxy_StreamRequest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ProxyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProxyServer).StreamRequest(m, &proxyStreamRequestServer{stream})
+}
+
+type Proxy_StreamRequestServer interface {
+	Send(*ProxyResponseChunk) error
+	grpc.ServerStream
+}
+
+type proxyStreamRequestServer struct {
+	grpc.ServerStream
+}
+
+func (x *proxyStreamRequestServer) Send(m *ProxyResponseChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Proxy_ServiceDesc is the grpc.ServiceDesc for Proxy service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -100,6 +160,12 @@ var Proxy_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _Proxy_SendRequest_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRequest",
+			Handler:       _Proxy_StreamRequest_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "service.proto",
 }
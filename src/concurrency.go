@@ -0,0 +1,36 @@
+package main
+
+import "sync/atomic"
+
+// wakes up the scheduler when a request finished, so that requests waiting for a busy proxy are dispatched
+var proxyReleased = make(chan struct{}, 1)
+
+// maxInFlight returns the concurrency cap of the proxy from its entry, its source or PROXY_MAX_IN_FLIGHT, zero is unlimited
+func (client *ProxyClient) maxInFlight() int64 {
+	if client.config.maxInFlight > 0 {
+		return int64(client.config.maxInFlight)
+	}
+
+	if limit, ok := globalConfiguration.SourceMaxInFlight[client.config.source]; ok && limit > 0 {
+		return int64(limit)
+	}
+
+	return int64(globalConfiguration.ProxyMaxInFlight)
+}
+
+// atCapacity reports whether the proxy carries as many requests as it may
+func (client *ProxyClient) atCapacity() bool {
+	limit := client.maxInFlight()
+
+	return limit > 0 && atomic.LoadInt64(&client.inFlight) >= limit
+}
+
+// release marks a request through the proxy as finished
+func (client *ProxyClient) release() {
+	atomic.AddInt64(&client.inFlight, -1)
+
+	select {
+	case proxyReleased <- struct{}{}:
+	default:
+	}
+}
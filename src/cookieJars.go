@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+type cookieJarKey struct {
+	// ProxyConfig.key of the proxy
+	proxy string
+	host  string
+}
+
+type hostCookieJar struct {
+	jar        *cookiejar.Jar
+	exitIp     string
+	lastUsedAt time.Time
+}
+
+// CookieJars keeps cookies received through every proxy from every host so that following requests send them back
+type CookieJars struct {
+	lock sync.Mutex
+	jars map[cookieJarKey]*hostCookieJar
+}
+
+var cookieJars = &CookieJars{
+	jars: make(map[cookieJarKey]*hostCookieJar),
+}
+
+// CookieJarView is what the admin endpoint shows about a single jar
+type CookieJarView struct {
+	Proxy      string            `json:"proxy"`
+	ExitIp     string            `json:"exitIp"`
+	Host       string            `json:"host"`
+	LastUsedAt time.Time         `json:"lastUsedAt"`
+	Cookies    map[string]string `json:"cookies"`
+}
+
+// cookieJar returns the jar of the proxy for the host of the request, nil when jars are disabled globally or for the request
+func (client *ProxyClient) cookieJar(req *ActiveRequest) http.CookieJar {
+	if !globalConfiguration.EnableCookieJar || req.DisableCookieJar {
+		return nil
+	}
+
+	key := cookieJarKey{proxy: client.config.key(), host: req.Url.Hostname()}
+
+	cookieJars.lock.Lock()
+	defer cookieJars.lock.Unlock()
+
+	jar, ok := cookieJars.jars[key]
+	if !ok {
+		// cookiejar.New only fails on invalid options
+		newJar, _ := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+		jar = &hostCookieJar{jar: newJar}
+		cookieJars.jars[key] = jar
+	}
+
+	jar.exitIp = client.exitIp().ip
+	jar.lastUsedAt = time.Now()
+
+	return jar.jar
+}
+
+// list returns jars matching the proxy and host, empty filters match everything
+func (jars *CookieJars) list(proxy string, host string) []CookieJarView {
+	jars.lock.Lock()
+	defer jars.lock.Unlock()
+
+	views := make([]CookieJarView, 0)
+	for key, jar := range jars.jars {
+		if !jar.matches(key, proxy, host) {
+			continue
+		}
+
+		cookies := make(map[string]string)
+		for _, scheme := range []string{"http", "https"} {
+			for _, cookie := range jar.jar.Cookies(&url.URL{Scheme: scheme, Host: key.host, Path: "/"}) {
+				cookies[cookie.Name] = cookie.Value
+			}
+		}
+
+		views = append(views, CookieJarView{
+			Proxy:      key.proxy,
+			ExitIp:     jar.exitIp,
+			Host:       key.host,
+			LastUsedAt: jar.lastUsedAt,
+			Cookies:    cookies,
+		})
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Proxy != views[j].Proxy {
+			return views[i].Proxy < views[j].Proxy
+		}
+
+		return views[i].Host < views[j].Host
+	})
+
+	return views
+}
+
+// clear removes jars matching the proxy and host and returns how many were removed
+func (jars *CookieJars) clear(proxy string, host string) int {
+	jars.lock.Lock()
+	defer jars.lock.Unlock()
+
+	removed := 0
+	for key, jar := range jars.jars {
+		if jar.matches(key, proxy, host) {
+			delete(jars.jars, key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// matches reports whether the jar belongs to the host and to the proxy given by its key or exit IP
+func (jar *hostCookieJar) matches(key cookieJarKey, proxy string, host string) bool {
+	return (host == "" || key.host == host) && (proxy == "" || key.proxy == proxy || jar.exitIp == proxy)
+}
+
+// runCookieJarCleaner removes jars not used for COOKIE_JAR_TTL
+func runCookieJarCleaner(ctx context.Context) {
+	if !globalConfiguration.EnableCookieJar {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cookieJars.lock.Lock()
+		expired := 0
+		for key, jar := range cookieJars.jars {
+			if time.Since(jar.lastUsedAt) > globalConfiguration.CookieJarTtl {
+				delete(cookieJars.jars, key)
+				expired++
+			}
+		}
+		cookieJars.lock.Unlock()
+
+		if expired > 0 {
+			log.Printf("Removed %d expired cookie jars", expired)
+		}
+	}
+}
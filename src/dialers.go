@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// Op of the net.OpError returned when the upstream proxy itself cannot be reached or refuses the connection
+const proxyConnectOp = "proxy connect"
+
+// how long connecting to the proxy and the handshake may take
+const proxyHandshakeTimeout = 10 * time.Second
+
+var proxyTypes = map[string]struct{}{
+	"socks5":  {},
+	"socks4":  {},
+	"socks4a": {},
+	"http":    {},
+	"https":   {},
+}
+
+func isValidProxyType(proxyType string) bool {
+	_, ok := proxyTypes[proxyType]
+	return ok
+}
+
+// proxyChain is the dialer pool proxies are reached over, proxy.Direct unless PROXY_CHAIN is set
+var proxyChain proxy.Dialer = proxy.Direct
+
+// loadProxyChain builds the dialer of PROXY_CHAIN, the first hop is dialed directly and every following one through the previous
+func loadProxyChain() error {
+	for _, entry := range globalConfiguration.ProxyChain {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		hop, err := parseProxyLine(entry)
+		if err != nil {
+			return fmt.Errorf("invalid PROXY_CHAIN hop %s: %w", entry, err)
+		}
+
+		proxyChain, err = createProxyDialer(hop, proxyChain)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Chaining proxies through %s proxy %s", hop.proxyType, hop.address())
+	}
+
+	return nil
+}
+
+// createProxyDialer returns a dialer connecting to targets through the given proxy, reaching the proxy over forward
+func createProxyDialer(config ProxyConfig, forward proxy.Dialer) (proxy.Dialer, error) {
+	address := net.JoinHostPort(config.host, strconv.FormatInt(config.port, 10))
+
+	switch config.proxyType {
+	case proxyTypeDirect:
+		return createDirectDialer(config, forward), nil
+	case "socks5":
+		var auth *proxy.Auth
+		if config.username != "" {
+			auth = &proxy.Auth{User: config.username, Password: config.password}
+		}
+
+		return proxy.SOCKS5("tcp", address, auth, forward)
+	case "socks4", "socks4a":
+		return &socks4Dialer{
+			address:      address,
+			userId:       config.username,
+			remoteLookup: config.proxyType == "socks4a",
+			forward:      forward,
+		}, nil
+	case "http", "https":
+		return &httpConnectDialer{
+			address:  address,
+			host:     config.host,
+			username: config.username,
+			password: config.password,
+			useTls:   config.proxyType == "https",
+			forward:  forward,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy type %s", config.proxyType)
+	}
+}
+
+func proxyConnectError(network string, err error) error {
+	return &net.OpError{Op: proxyConnectOp, Net: network, Err: err}
+}
+
+// httpConnectDialer tunnels connections through a HTTP proxy using CONNECT, optionally talking TLS to the proxy
+type httpConnectDialer struct {
+	address  string
+	host     string
+	username string
+	password string
+	useTls   bool
+	forward  proxy.Dialer
+}
+
+func (dialer *httpConnectDialer) Dial(network, addr string) (net.Conn, error) {
+	conn, err := dialer.forward.Dial("tcp", dialer.address)
+	if err != nil {
+		return nil, proxyConnectError(network, err)
+	}
+
+	err = conn.SetDeadline(time.Now().Add(proxyHandshakeTimeout))
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+
+	if dialer.useTls {
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName:         dialer.host,
+			InsecureSkipVerify: globalConfiguration.ProxyTlsSkipVerify,
+		})
+
+		err = tlsConn.Handshake()
+		if err != nil {
+			_ = conn.Close()
+			return nil, proxyConnectError(network, err)
+		}
+
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+
+	if dialer.username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(dialer.username + ":" + dialer.password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	err = req.Write(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, fmt.Errorf("proxy responded to CONNECT with %s", resp.Status))
+	}
+
+	err = conn.SetDeadline(time.Time{})
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn keeps bytes the proxy sent right after its CONNECT response
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (conn *bufferedConn) Read(p []byte) (int, error) {
+	return conn.reader.Read(p)
+}
+
+// socks4Dialer implements SOCKS4 and SOCKS4a, which lets the proxy resolve target hostnames
+type socks4Dialer struct {
+	address      string
+	userId       string
+	remoteLookup bool
+	forward      proxy.Dialer
+}
+
+func (dialer *socks4Dialer) Dial(network, addr string) (net.Conn, error) {
+	host, portString, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	request := []byte{4, 1, 0, 0}
+	binary.BigEndian.PutUint16(request[2:], uint16(port))
+
+	ip := net.ParseIP(host).To4()
+	if ip == nil && !dialer.remoteLookup {
+		ips, err := net.LookupIP(host)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, candidate := range ips {
+			if ip = candidate.To4(); ip != nil {
+				break
+			}
+		}
+
+		if ip == nil {
+			return nil, fmt.Errorf("no IPv4 address for %s, SOCKS4 supports only IPv4", host)
+		}
+	}
+
+	if ip == nil {
+		// 0.0.0.x tells a SOCKS4a proxy that the hostname follows the user id
+		request = append(request, 0, 0, 0, 1)
+		request = append(request, dialer.userId...)
+		request = append(request, 0)
+		request = append(request, host...)
+		request = append(request, 0)
+	} else {
+		request = append(request, ip...)
+		request = append(request, dialer.userId...)
+		request = append(request, 0)
+	}
+
+	conn, err := dialer.forward.Dial("tcp", dialer.address)
+	if err != nil {
+		return nil, proxyConnectError(network, err)
+	}
+
+	err = conn.SetDeadline(time.Now().Add(proxyHandshakeTimeout))
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+
+	_, err = conn.Write(request)
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+
+	response := make([]byte, 8)
+	_, err = io.ReadFull(conn, response)
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+
+	if response[1] != 90 {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, fmt.Errorf("socks4 request rejected with code %d", response[1]))
+	}
+
+	err = conn.SetDeadline(time.Time{})
+	if err != nil {
+		_ = conn.Close()
+		return nil, proxyConnectError(network, err)
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/net/proxy"
+)
+
+// pool members of this type send requests from this host instead of through a proxy
+const proxyTypeDirect = "direct"
+
+// directSource returns the source of direct pool members, one per DIRECT_SOURCE_IPS entry or a single one using the default route
+func directSource(name string) (ProxySource, error) {
+	for _, ip := range globalConfiguration.DirectSourceIps {
+		if net.ParseIP(ip) == nil {
+			return ProxySource{}, fmt.Errorf("invalid DIRECT_SOURCE_IPS entry %s", ip)
+		}
+	}
+
+	// every member would leave through the chain with the same exit IP, the source IPs would only bind the first hop
+	if len(globalConfiguration.DirectSourceIps) > 0 && proxyChain != proxy.Direct {
+		return ProxySource{}, errors.New("DIRECT_SOURCE_IPS cannot be combined with PROXY_CHAIN")
+	}
+
+	if globalConfiguration.DirectWeight <= 0 {
+		return ProxySource{}, fmt.Errorf("DIRECT_WEIGHT must be positive, got %v", globalConfiguration.DirectWeight)
+	}
+
+	return ProxySource{
+		name:   name,
+		tags:   splitTags(globalConfiguration.ProxySourceTags[name]),
+		direct: true,
+	}, nil
+}
+
+func directConfigs(source string) []ProxyConfig {
+	sourceIps := globalConfiguration.DirectSourceIps
+	if len(sourceIps) == 0 {
+		sourceIps = []string{""}
+	}
+
+	configs := make([]ProxyConfig, 0, len(sourceIps))
+	for _, ip := range sourceIps {
+		configs = append(configs, ProxyConfig{
+			host:      ip,
+			proxyType: proxyTypeDirect,
+			source:    source,
+			weight:    globalConfiguration.DirectWeight,
+		})
+	}
+
+	return configs
+}
+
+// createDirectDialer connects to targets from this host, bound to the source IP in config.host when set.
+// When proxies are chained, direct traffic leaves through the chain as well.
+func createDirectDialer(config ProxyConfig, forward proxy.Dialer) proxy.Dialer {
+	if forward != proxy.Direct {
+		return forward
+	}
+
+	dialer := &net.Dialer{
+		Timeout: proxyHandshakeTimeout,
+	}
+
+	if config.host != "" {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(config.host)}
+	}
+
+	return dialer
+}
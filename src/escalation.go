@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// EscalationPolicy sends requests to matching hosts through the first tier of proxies and moves them to the next tier when they get blocked
+type EscalationPolicy struct {
+	Hosts []string `json:"hosts"`
+	// every tier is a set of proxy tags, cheapest first
+	Tiers [][]string `json:"tiers"`
+	// status codes considered a block, 403 and 429 by default
+	EscalateOn []int `json:"escalateOn"`
+	// response headers and values marking a challenge page, cf-mitigated: challenge by default
+	ChallengeHeaders map[string]string `json:"challengeHeaders"`
+	// substrings of the body marking a challenge page, only checked for responses that are not streamed
+	ChallengeBodies []string `json:"challengeBodies"`
+
+	patterns []hostPattern
+}
+
+var escalationPolicies []*EscalationPolicy
+
+// loadEscalationPolicies reads ESCALATION_POLICIES_FILE, the first policy matching the host of a request is used
+func loadEscalationPolicies() error {
+	if globalConfiguration.EscalationPoliciesFile == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(globalConfiguration.EscalationPoliciesFile)
+	if err != nil {
+		return err
+	}
+
+	policies := make([]*EscalationPolicy, 0)
+	err = json.Unmarshal(content, &policies)
+	if err != nil {
+		return fmt.Errorf("invalid escalation policies %s: %w", globalConfiguration.EscalationPoliciesFile, err)
+	}
+
+	for i, policy := range policies {
+		if len(policy.Tiers) == 0 {
+			return fmt.Errorf("escalation policy %d has no tiers", i)
+		}
+
+		for _, host := range policy.Hosts {
+			pattern, err := parseHostPattern(host)
+			if err != nil {
+				return fmt.Errorf("escalation policy %d: %w", i, err)
+			}
+
+			policy.patterns = append(policy.patterns, pattern)
+		}
+
+		if policy.EscalateOn == nil {
+			policy.EscalateOn = []int{http.StatusForbidden, http.StatusTooManyRequests}
+		}
+
+		if policy.ChallengeHeaders == nil {
+			policy.ChallengeHeaders = map[string]string{"cf-mitigated": "challenge"}
+		}
+	}
+
+	escalationPolicies = policies
+	log.Printf("Loaded %d escalation policies", len(policies))
+
+	return nil
+}
+
+func findEscalationPolicy(host string) *EscalationPolicy {
+	for _, policy := range escalationPolicies {
+		for _, pattern := range policy.patterns {
+			if pattern.matches(host) {
+				return policy
+			}
+		}
+	}
+
+	return nil
+}
+
+// isBlocked reports whether the response means the proxy got blocked by the target
+func (policy *EscalationPolicy) isBlocked(resp *Response) bool {
+	if resp == nil || resp.Status != ResponseStatusOk {
+		return false
+	}
+
+	for _, code := range policy.EscalateOn {
+		if resp.Code == code {
+			return true
+		}
+	}
+
+	for key, value := range policy.ChallengeHeaders {
+		if strings.EqualFold(resp.Headers.Get(key), value) {
+			return true
+		}
+	}
+
+	for _, marker := range policy.ChallengeBodies {
+		if bytes.Contains(resp.Body, []byte(marker)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tierTags returns the proxy tags of the tier the request is currently at
+func (request *ActiveRequest) tierTags() []string {
+	if request.Policy == nil {
+		return nil
+	}
+
+	return request.Policy.Tiers[request.Tier]
+}
+
+// escalate moves a blocked request to the next tier, it stays at the last one
+func (request *ActiveRequest) escalate() {
+	if request.Tier+1 >= len(request.Policy.Tiers) {
+		return
+	}
+
+	request.Tier++
+	log.Printf("%s blocked, escalating to tier %d (%s)", request.Url.String(), request.Tier, strings.Join(request.tierTags(), ", "))
+}
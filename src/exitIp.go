@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/throttled/throttled"
+)
+
+const (
+	// proxies exiting through the same IP share rate limiters
+	DuplicateExitIpsShare = "share"
+	// only the first proxy exiting through an IP is kept in the pool
+	DuplicateExitIpsDrop = "drop"
+)
+
+func isValidDuplicateExitIps(mode string) bool {
+	return mode == DuplicateExitIpsShare || mode == DuplicateExitIpsDrop
+}
+
+// ExitIp groups proxies exiting through the same IP, rate limits and geo information belong to the IP rather than to the proxy
+type ExitIp struct {
+	ip string
+	// one limiter per quota of the rate limit rules, all keyed by host in the same store
+	limiters     map[string]*throttled.GCRARateLimiter
+	limitersLock sync.Mutex
+	// state of the limiters, kept across restarts in the state file
+	store *LimiterStore
+	geo   GeoInfo
+	// proxies of the pool currently exiting through this IP, guarded by the pool lock
+	clients map[*ProxyClient]struct{}
+}
+
+func (client *ProxyClient) exitIp() *ExitIp {
+	return client.exit.Load()
+}
+
+// limiterFor returns the limiter of the rate limit rule matching the host, nil when the host is unlimited
+func (group *ExitIp) limiterFor(host string) *throttled.GCRARateLimiter {
+	rule := rateLimitRuleFor(host)
+	if rule.Unlimited {
+		return nil
+	}
+
+	key := rule.quotaKey()
+
+	group.limitersLock.Lock()
+	defer group.limitersLock.Unlock()
+
+	limiter, ok := group.limiters[key]
+	if !ok {
+		limiter = createLimiter(group.store, rule.quota())
+		group.limiters[key] = limiter
+	}
+
+	return limiter
+}
+
+// sharedWith returns the number of other proxies of the pool exiting through the same IP as the proxy
+func (pool *ProxyPool) sharedWith(client *ProxyClient) int {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	group := client.exitIp()
+	if _, ok := group.clients[client]; ok {
+		return len(group.clients) - 1
+	}
+
+	return len(group.clients)
+}
+
+// joinExitIp assigns the proxy to the group of its exit IP, pool lock must be held.
+// Returns false when duplicates are dropped and another proxy already exits through the IP.
+func (pool *ProxyPool) joinExitIp(client *ProxyClient, ip string) bool {
+	group, ok := pool.exitIps[ip]
+	if !ok {
+		store := restoredLimiterStore(ip)
+		group = &ExitIp{
+			ip:       ip,
+			limiters: make(map[string]*throttled.GCRARateLimiter),
+			store:    store,
+			geo:      lookupGeoInfo(ip),
+			clients:  make(map[*ProxyClient]struct{}),
+		}
+		pool.exitIps[ip] = group
+	}
+
+	if len(group.clients) > 0 && globalConfiguration.DuplicateExitIps == DuplicateExitIpsDrop {
+		return false
+	}
+
+	if len(group.clients) > 0 {
+		log.Printf("Proxy %s shares exit IP %s with %d other proxies", client.config.address(), ip, len(group.clients))
+	}
+
+	group.clients[client] = struct{}{}
+	client.exit.Store(group)
+
+	return true
+}
+
+// leaveExitIp removes the proxy from the group of its exit IP, pool lock must be held.
+// The proxy keeps pointing to the group so the scheduler can still use it until it gets the new proxy list.
+func (pool *ProxyPool) leaveExitIp(client *ProxyClient) {
+	group := client.exitIp()
+	if group == nil {
+		return
+	}
+
+	delete(group.clients, client)
+	if len(group.clients) == 0 && pool.exitIps[group.ip] == group {
+		delete(pool.exitIps, group.ip)
+	}
+}
+
+// changeExitIp moves a proxy whose exit IP changed since it was connected to the group of the new IP.
+// Returns false when a gateway client landed on an IP used by another proxy while duplicates are dropped,
+// the client keeps its previous group then and should rotate its session again.
+func (pool *ProxyPool) changeExitIp(client *ProxyClient, ip string) bool {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	key := client.config.key()
+	if pool.clients[key] != client || client.exitIp().ip == ip {
+		return true
+	}
+
+	if client.gateway.Load() != nil && globalConfiguration.DuplicateExitIps == DuplicateExitIpsDrop {
+		if group, ok := pool.exitIps[ip]; ok && len(group.clients) > 0 {
+			return false
+		}
+	}
+
+	log.Printf("Proxy %s exit IP changed from %s to %s", client.config.address(), client.exitIp().ip, ip)
+
+	pool.leaveExitIp(client)
+	if !pool.joinExitIp(client, ip) {
+		log.Printf("Proxy %s now exits through %s used by another proxy, dropping it", client.config.address(), ip)
+		delete(pool.clients, key)
+		go client.retire(context.Background())
+	}
+
+	client.setId(ip)
+	pool.publish()
+
+	return true
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+	"strings"
+
+	"golang.org/x/net/proxy"
+)
+
+// placeholder in the username of gateway entries replaced with a random session id of every virtual client
+const gatewaySessionPlaceholder = "{session}"
+
+// placeholder in the username of gateway entries replaced with the lowercase country of the entry
+const gatewayCountryPlaceholder = "{country}"
+
+// GatewaySession is the current session of a virtual client of a gateway
+type GatewaySession struct {
+	id     string
+	dialer proxy.Dialer
+}
+
+// isGateway reports whether the entry is a gateway that encodes a session in the username
+func (config ProxyConfig) isGateway() bool {
+	return strings.Contains(config.username, gatewaySessionPlaceholder)
+}
+
+// expandGateway returns one virtual entry per gateway session, other entries are returned as they are
+func expandGateway(config ProxyConfig) []ProxyConfig {
+	if !config.isGateway() {
+		return []ProxyConfig{config}
+	}
+
+	sessions := config.sessions
+	if sessions <= 0 {
+		sessions = globalConfiguration.GatewaySessions
+	}
+
+	configs := make([]ProxyConfig, 0, sessions)
+	for slot := 1; slot <= sessions; slot++ {
+		virtual := config
+		virtual.gatewaySlot = slot
+		configs = append(configs, virtual)
+	}
+
+	return configs
+}
+
+func newGatewaySessionId() string {
+	id := make([]byte, 6)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// gateway rotations landing on an exit IP used by another proxy are retried this many times
+const gatewayRotateAttempts = 5
+
+// newGatewaySession switches the virtual client to a new session, new connections are dialed through it
+func (client *ProxyClient) newGatewaySession(id string) error {
+	session := &GatewaySession{id: id}
+
+	config := client.config
+	config.username = strings.ReplaceAll(config.username, gatewaySessionPlaceholder, session.id)
+	config.username = strings.ReplaceAll(config.username, gatewayCountryPlaceholder, strings.ToLower(config.country))
+
+	dialer, err := createProxyDialer(config, proxyChain)
+	if err != nil {
+		return err
+	}
+
+	session.dialer = dialer
+	client.gateway.Store(session)
+
+	return nil
+}
+
+// dialGateway connects through the current session of the virtual client
+func (client *ProxyClient) dialGateway(network, addr string) (net.Conn, error) {
+	return client.gateway.Load().dialer.Dial(network, addr)
+}
+
+// rotateGatewaySession moves the virtual client to a new session and learns its new exit IP.
+// Rotations requested while one is running are ignored.
+func (client *ProxyClient) rotateGatewaySession(ctx context.Context, reason string) {
+	if client.gateway.Load() == nil || !client.rotating.CompareAndSwap(false, true) {
+		return
+	}
+	defer client.rotating.Store(false)
+
+	for attempt := 1; attempt <= gatewayRotateAttempts; attempt++ {
+		previous := client.gateway.Load().id
+
+		err := client.newGatewaySession(newGatewaySessionId())
+		if err != nil {
+			log.Printf("Error rotating gateway session of %s: %v", client.config.address(), err)
+			return
+		}
+
+		// connections of the previous session must not be reused
+		client.httpClient.CloseIdleConnections()
+		client.http2Client.CloseIdleConnections()
+
+		log.Printf("Rotated gateway session of %s from %s to %s: %s", client.config.address(), previous, client.gateway.Load().id, reason)
+
+		// cookies belong to the previous session
+		cookieJars.clear(client.config.key(), "")
+
+		ip, err := getExternalProxyIp(client, ctx)
+		if err != nil {
+			log.Printf("Error detecting exit IP of %s after rotation: %v", client.config.address(), err)
+			// the client keeps the previous exit IP until a quarantine probe learns the new one
+			client.recordFailure("exit IP unknown after rotation")
+			return
+		}
+
+		if proxyPool.changeExitIp(client, *ip) {
+			return
+		}
+
+		reason = "exit IP " + *ip + " used by another proxy"
+	}
+
+	log.Printf("Gateway session of %s kept landing on exit IPs used by other proxies", client.config.address())
+}
+
+// rotatesOn reports whether the response should make a gateway client rotate its session
+func (client *ProxyClient) rotatesOn(request *ActiveRequest, resp *Response) bool {
+	if client.gateway.Load() == nil || resp == nil || resp.Status != ResponseStatusOk {
+		return false
+	}
+
+	if request.Policy != nil && request.Policy.isBlocked(resp) {
+		return true
+	}
+
+	for _, code := range globalConfiguration.GatewayRotateOn {
+		if resp.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rotateGateways rotates sessions of gateway clients matching the proxy exit IP or key, an empty filter matches all
+func (pool *ProxyPool) rotateGateways(ctx context.Context, filter string) int {
+	rotated := 0
+	for _, client := range pool.all() {
+		if client.gateway.Load() == nil {
+			continue
+		}
+
+		if filter != "" && filter != client.config.key() && filter != client.exitIp().ip {
+			continue
+		}
+
+		go client.rotateGatewaySession(ctx, "requested")
+		rotated++
+	}
+
+	return rotated
+}
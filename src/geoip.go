@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoInfo describes where the exit IP of a proxy is located
+type GeoInfo struct {
+	country      string
+	asn          uint
+	organization string
+}
+
+// geoRecord covers fields of both country/city and ASN databases, a lookup fills whatever the database has
+type geoRecord struct {
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	AutonomousSystemNumber       uint   `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+var geoDatabases []*maxminddb.Reader
+
+// loadGeoDatabases opens the .mmdb files from GEOIP_DATABASES, GeoIP lookups are skipped when none is configured
+func loadGeoDatabases() {
+	for _, path := range globalConfiguration.GeoipDatabases {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		db, err := maxminddb.Open(path)
+		if err != nil {
+			log.Fatalf("Error opening GeoIP database %s: %v", path, err)
+		}
+
+		log.Printf("Loaded GeoIP database %s (%s)", path, db.Metadata.DatabaseType)
+		geoDatabases = append(geoDatabases, db)
+	}
+}
+
+// lookupGeoInfo annotates an exit IP using all loaded databases
+func lookupGeoInfo(ip string) GeoInfo {
+	info := GeoInfo{}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return info
+	}
+
+	for _, db := range geoDatabases {
+		var record geoRecord
+		err := db.Lookup(parsed, &record)
+		if err != nil {
+			log.Printf("Error looking up %s in GeoIP database: %v", ip, err)
+			continue
+		}
+
+		if record.Country.IsoCode != "" {
+			info.country = record.Country.IsoCode
+		}
+
+		if record.AutonomousSystemNumber != 0 {
+			info.asn = record.AutonomousSystemNumber
+			info.organization = record.AutonomousSystemOrganization
+		}
+	}
+
+	return info
+}
+
+// country of the exit IP, falls back to the country from the proxy list
+func (client *ProxyClient) country() string {
+	if geo := client.exitIp().geo; geo.country != "" {
+		return geo.country
+	}
+
+	return client.config.country
+}
+
+// inCountries reports whether the proxy exits in one of the countries, any proxy matches an empty list
+func (client *ProxyClient) inCountries(countries []string) bool {
+	if len(countries) == 0 {
+		return true
+	}
+
+	country := client.country()
+	for _, candidate := range countries {
+		if strings.EqualFold(candidate, country) {
+			return true
+		}
+	}
+
+	return false
+}
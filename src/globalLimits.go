@@ -0,0 +1,160 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/throttled/throttled"
+)
+
+// GlobalLimit caps requests of the whole pool to a single target host, on top of the limits of every exit IP
+type GlobalLimit struct {
+	host        string
+	quota       throttled.RateQuota
+	description string
+	limiter     *throttled.GCRARateLimiter
+	// same quota over a store that is never written, tells whether a request would pass without using it up
+	peeker *throttled.GCRARateLimiter
+	// requests sent and requests that had to wait at least once
+	sent    int64
+	delayed int64
+}
+
+// GlobalLimits holds the global limit of every host seen so far, the limiter state of all of them is kept in one store keyed by host
+type GlobalLimits struct {
+	lock  sync.Mutex
+	store *LimiterStore
+	hosts map[string]*GlobalLimit
+}
+
+var globalLimits = &GlobalLimits{
+	store: newLimiterStore(),
+	hosts: make(map[string]*GlobalLimit),
+}
+
+// readOnlyLimiterStore lets a limiter compute its result without storing it
+type readOnlyLimiterStore struct {
+	*LimiterStore
+}
+
+func (store readOnlyLimiterStore) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (store readOnlyLimiterStore) CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+// limit returns the global limit of the host according to the current rate limit rules, nil when it has none
+func (limits *GlobalLimits) limit(host string) *GlobalLimit {
+	quota, description, ok := rateLimitRuleFor(host).globalQuota()
+
+	limits.lock.Lock()
+	defer limits.lock.Unlock()
+
+	limit, exists := limits.hosts[host]
+	if !ok {
+		if exists {
+			delete(limits.hosts, host)
+		}
+
+		return nil
+	}
+
+	// a changed rule keeps the counters and the limiter state, which is stored by host
+	if !exists || limit.quota != quota {
+		updated := &GlobalLimit{
+			host:        host,
+			quota:       quota,
+			description: description,
+			limiter:     createLimiter(limits.store, quota),
+			peeker:      createLimiter(readOnlyLimiterStore{limits.store}, quota),
+		}
+
+		if exists {
+			updated.sent = atomic.LoadInt64(&limit.sent)
+			updated.delayed = atomic.LoadInt64(&limit.delayed)
+		}
+
+		limit = updated
+		limits.hosts[host] = limit
+	}
+
+	return limit
+}
+
+// peek reports whether a request to the host would be limited now, without counting it
+func (limit *GlobalLimit) peek() (bool, throttled.RateLimitResult, error) {
+	return limit.peeker.RateLimit(limit.host, 1)
+}
+
+// take counts a request to the host, it is only called after peek allowed it
+func (limit *GlobalLimit) take() error {
+	_, _, err := limit.limiter.RateLimit(limit.host, 1)
+	if err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&limit.sent, 1)
+
+	return nil
+}
+
+// GlobalLimitView is what the dashboard shows about the global limit of a host
+type GlobalLimitView struct {
+	Host      string
+	Quota     string
+	Remaining int
+	// empty when a request may be sent right away
+	NextIn  string
+	Sent    int64
+	Delayed int64
+}
+
+func (limit *GlobalLimit) view() GlobalLimitView {
+	view := GlobalLimitView{
+		Host:    limit.host,
+		Quota:   limit.description,
+		Sent:    atomic.LoadInt64(&limit.sent),
+		Delayed: atomic.LoadInt64(&limit.delayed),
+	}
+
+	_, result, err := limit.peeker.RateLimit(limit.host, 0)
+	if err == nil {
+		view.Remaining = result.Remaining
+	}
+
+	limited, result, err := limit.peek()
+	if err == nil && limited {
+		view.NextIn = result.RetryAfter.Round(time.Millisecond).String()
+	}
+
+	return view
+}
+
+// views returns hosts having a global limit, those sent the most requests first
+func (limits *GlobalLimits) views() []GlobalLimitView {
+	limits.lock.Lock()
+	items := make([]*GlobalLimit, 0, len(limits.hosts))
+	for _, limit := range limits.hosts {
+		items = append(items, limit)
+	}
+	limits.lock.Unlock()
+
+	views := make([]GlobalLimitView, 0, len(items))
+	for _, limit := range items {
+		views = append(views, limit.view())
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Sent != views[j].Sent {
+			return views[i].Sent > views[j].Sent
+		}
+
+		return views[i].Host < views[j].Host
+	})
+
+	return views
+}
@@ -3,11 +3,13 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"io"
 	"log"
 	"math"
 	"net"
+	"net/http"
 	url2 "net/url"
 	"strings"
 
@@ -19,6 +21,9 @@ type server struct {
 	pb.UnimplementedProxyServer
 }
 
+// chunk size of body messages sent by StreamRequest
+const streamChunkSize = 32 * 1024
+
 func createProxyErrorResp(errorType pb.ProxyResponseError_ErrorType) *pb.ProxyResponse {
 	return &pb.ProxyResponse{
 		Response: &pb.ProxyResponse_Error{
@@ -29,8 +34,21 @@ func createProxyErrorResp(errorType pb.ProxyResponseError_ErrorType) *pb.ProxyRe
 	}
 }
 
-func (s *server) SendRequest(ctx context.Context, in *pb.ProxyRequest) (*pb.ProxyResponse, error) {
-	//return &pb.ProxyResponse{Message: "Hello " + in.GetName()}, nil
+func createProxyErrorChunk(errorType pb.ProxyResponseError_ErrorType) *pb.ProxyResponseChunk {
+	return &pb.ProxyResponseChunk{
+		Chunk: &pb.ProxyResponseChunk_Error{
+			Error: &pb.ProxyResponseError{
+				ErrorType: errorType,
+			},
+		},
+	}
+}
+
+// sendProtoRequest queues the request and waits for the proxied response. A nil response with a nil error type means the request got cancelled.
+func sendProtoRequest(ctx context.Context, in *pb.ProxyRequest, stream bool) (*Response, *pb.ProxyResponseError_ErrorType) {
+	fail := func(errorType pb.ProxyResponseError_ErrorType) (*Response, *pb.ProxyResponseError_ErrorType) {
+		return nil, &errorType
+	}
 
 	priority := int64(0)
 	if in.Priority != nil {
@@ -39,7 +57,7 @@ func (s *server) SendRequest(ctx context.Context, in *pb.ProxyRequest) (*pb.Prox
 
 	url, err := url2.Parse(in.GetUrl())
 	if err != nil || url.Scheme == "" || url.Host == "" {
-		return createProxyErrorResp(pb.ProxyResponseError_INVALID_URL), nil
+		return fail(pb.ProxyResponseError_INVALID_URL)
 	}
 
 	retryOnCodes := make([]uint16, 0)
@@ -49,10 +67,32 @@ func (s *server) SendRequest(ctx context.Context, in *pb.ProxyRequest) (*pb.Prox
 		}
 	}
 
-	_, respChan, err := initializeRequest(url, priority, retryOnCodes, ctx)
+	requestHeaders := http.Header{}
+	for key, value := range in.GetHeaders() {
+		requestHeaders.Set(key, value)
+	}
+
+	_, respChan, err := initializeRequest(RequestOptions{
+		Url:              url,
+		Method:           in.GetMethod(),
+		Headers:          requestHeaders,
+		Body:             in.GetBody(),
+		Priority:         priority,
+		RetryOnCodes:     retryOnCodes,
+		Stream:           stream,
+		ProxyTags:        in.GetProxyTags(),
+		PreferProxyTags:  in.GetPreferProxyTags(),
+		ProxyCountries:   in.GetProxyCountries(),
+		SessionId:        in.GetSessionId(),
+		DisableCookieJar: in.GetDisableCookieJar(),
+	}, ctx)
+	if errors.Is(err, errInvalidMethod) {
+		return fail(pb.ProxyResponseError_INVALID_METHOD)
+	}
+
 	if err != nil {
 		log.Printf("ERROR %s: %v", url.String(), err)
-		return createProxyErrorResp(pb.ProxyResponseError_PROXY_ERROR), nil
+		return fail(pb.ProxyResponseError_PROXY_ERROR)
 	}
 
 	proxiedResp := <-respChan
@@ -61,39 +101,63 @@ func (s *server) SendRequest(ctx context.Context, in *pb.ProxyRequest) (*pb.Prox
 	}
 
 	if proxiedResp.Status == ResponseStatusTimeout {
-		return createProxyErrorResp(pb.ProxyResponseError_REMOTE_HOST_TIMED_OUT), nil
+		return fail(pb.ProxyResponseError_REMOTE_HOST_TIMED_OUT)
 	}
 
 	if proxiedResp.Status == ResponseStatusHostUnreachable {
-		return createProxyErrorResp(pb.ProxyResponseError_REMOTE_HOST_UNREACHABLE), nil
+		return fail(pb.ProxyResponseError_REMOTE_HOST_UNREACHABLE)
+	}
+
+	return proxiedResp, nil
+}
+
+func protoHeaderValues(header http.Header) map[string]*pb.HeaderValues {
+	headerValues := make(map[string]*pb.HeaderValues)
+
+	for key, values := range header {
+		if strings.ToLower(key) == "transfer-encoding" || strings.ToLower(key) == "content-length" {
+			continue
+		}
+
+		headerValues[key] = &pb.HeaderValues{Values: values}
+	}
+
+	return headerValues
+}
+
+func (s *server) SendRequest(ctx context.Context, in *pb.ProxyRequest) (*pb.ProxyResponse, error) {
+	proxiedResp, errorType := sendProtoRequest(ctx, in, false)
+	if errorType != nil {
+		return createProxyErrorResp(*errorType), nil
+	}
+
+	if proxiedResp == nil {
+		return nil, nil
 	}
 
 	reader := bytes.NewReader(proxiedResp.Body)
 
 	body, err := io.ReadAll(reader)
 	if err != nil {
-		log.Printf("ERROR %s: %v", url.String(), err)
+		log.Printf("ERROR %s: %v", in.GetUrl(), err)
 		return createProxyErrorResp(pb.ProxyResponseError_PROXY_ERROR), nil
 	}
 
+	headerValues := protoHeaderValues(proxiedResp.Headers)
 	headers := make(map[string]string)
 
-	for key, values := range proxiedResp.Headers {
-		if strings.ToLower(key) == "transfer-encoding" || strings.ToLower(key) == "content-length" {
-			continue
-		}
-
-		for _, value := range values {
-			headers[key] = value
-		}
+	for key, values := range headerValues {
+		headers[key] = values.Values[len(values.Values)-1]
 	}
 
 	response := &pb.ProxyResponse{
 		Response: &pb.ProxyResponse_Success{
 			Success: &pb.ProxyResponseSuccess{
-				Body:    body,
-				Status:  int32(proxiedResp.Code),
-				Headers: headers,
+				Body:           body,
+				Status:         int32(proxiedResp.Code),
+				Headers:        headers,
+				HeaderValues:   headerValues,
+				SessionChanged: proxiedResp.SessionChanged,
 			},
 		},
 	}
@@ -101,6 +165,63 @@ func (s *server) SendRequest(ctx context.Context, in *pb.ProxyRequest) (*pb.Prox
 	return response, nil
 }
 
+func (s *server) StreamRequest(in *pb.ProxyRequest, stream pb.Proxy_StreamRequestServer) error {
+	proxiedResp, errorType := sendProtoRequest(stream.Context(), in, true)
+	if errorType != nil {
+		return stream.Send(createProxyErrorChunk(*errorType))
+	}
+
+	if proxiedResp == nil {
+		return nil
+	}
+
+	body := proxiedResp.BodyStream
+	if body == nil {
+		body = io.NopCloser(bytes.NewReader(proxiedResp.Body))
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Error closing body in StreamRequest: %v", err)
+		}
+	}(body)
+
+	err := stream.Send(&pb.ProxyResponseChunk{
+		Chunk: &pb.ProxyResponseChunk_Head{
+			Head: &pb.ProxyResponseHead{
+				Status:         int32(proxiedResp.Code),
+				HeaderValues:   protoHeaderValues(proxiedResp.Headers),
+				SessionChanged: proxiedResp.SessionChanged,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, streamChunkSize)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			sendErr := stream.Send(&pb.ProxyResponseChunk{
+				Chunk: &pb.ProxyResponseChunk_Body{Body: buf[:n]},
+			})
+			if sendErr != nil {
+				return sendErr
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			log.Printf("ERROR streaming %s: %v", in.GetUrl(), err)
+			return stream.Send(createProxyErrorChunk(pb.ProxyResponseError_PROXY_ERROR))
+		}
+	}
+}
+
 func runGrpcProxy(ctx context.Context) {
 	flag.Parse()
 	lis, err := net.Listen("tcp", ":8082")
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// hopByHopHeaders are only meaningful for a single connection and must not be forwarded by proxies (RFC 7230)
+var hopByHopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// removeHopByHopHeaders deletes hop-by-hop headers, including the ones listed in Connection, in place
+func removeHopByHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
+
+// filterResponseHeaders returns the end-to-end response headers permitted by the allow and deny lists
+func filterResponseHeaders(header http.Header) http.Header {
+	filtered := header.Clone()
+	removeHopByHopHeaders(filtered)
+
+	if len(globalConfiguration.ResponseHeaderAllowList) > 0 {
+		allowed := make(map[string]struct{}, len(globalConfiguration.ResponseHeaderAllowList))
+		for _, name := range globalConfiguration.ResponseHeaderAllowList {
+			allowed[http.CanonicalHeaderKey(strings.TrimSpace(name))] = struct{}{}
+		}
+
+		for name := range filtered {
+			if _, ok := allowed[name]; !ok {
+				filtered.Del(name)
+			}
+		}
+	}
+
+	for _, name := range globalConfiguration.ResponseHeaderDenyList {
+		filtered.Del(strings.TrimSpace(name))
+	}
+
+	return filtered
+}
+
+// splitHeaderList splits a comma separated header value and drops empty items
+func splitHeaderList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ProxyHealth is the result of periodic health checks of a proxy
+type ProxyHealth struct {
+	lock                 sync.Mutex
+	healthy              bool
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	// duration of the last successful check
+	latency     time.Duration
+	lastCheckAt time.Time
+}
+
+func (client *ProxyClient) isHealthy() bool {
+	client.health.lock.Lock()
+	defer client.health.lock.Unlock()
+
+	return client.health.healthy
+}
+
+// checkHealth requests the health check URL through the proxy and returns whether the proxy changed its health
+func (client *ProxyClient) checkHealth(ctx context.Context, uri *url.URL) bool {
+	start := time.Now()
+	resp, err := client.probe(ctx, uri, globalConfiguration.HealthCheckTimeout)
+	latency := time.Since(start)
+
+	ok := err == nil && resp.Status == ResponseStatusOk && resp.Code >= 200 && resp.Code < 400
+
+	// health check URLs returning the caller IP, like the default one, reveal exit IP changes
+	if ok {
+		if ip := net.ParseIP(strings.TrimSpace(string(resp.Body))); ip != nil {
+			if !proxyPool.changeExitIp(client, ip.String()) {
+				go client.rotateGatewaySession(ctx, "exit IP used by another proxy")
+			}
+		}
+	}
+
+	client.health.lock.Lock()
+	defer client.health.lock.Unlock()
+
+	client.health.lastCheckAt = time.Now()
+
+	if ok {
+		client.health.latency = latency
+		client.health.consecutiveFailures = 0
+		client.health.consecutiveSuccesses++
+	} else {
+		client.health.consecutiveSuccesses = 0
+		client.health.consecutiveFailures++
+	}
+
+	if client.health.healthy && client.health.consecutiveFailures >= globalConfiguration.HealthCheckFailures {
+		log.Printf("Proxy %s failed %d health checks, removing it from the pool", client.id(), client.health.consecutiveFailures)
+		client.health.healthy = false
+		return true
+	}
+
+	if !client.health.healthy && client.health.consecutiveSuccesses >= globalConfiguration.HealthCheckSuccesses {
+		log.Printf("Proxy %s passed %d health checks, adding it back to the pool", client.id(), client.health.consecutiveSuccesses)
+		client.health.healthy = true
+		return true
+	}
+
+	return false
+}
+
+// runHealthChecker periodically checks all proxies of the pool and publishes the healthy ones
+func runHealthChecker(ctx context.Context) {
+	if globalConfiguration.HealthCheckInterval <= 0 {
+		return
+	}
+
+	uri, err := url.Parse(globalConfiguration.HealthCheckUrl)
+	if err != nil || uri.Host == "" {
+		log.Fatalf("Invalid HEALTH_CHECK_URL %s", globalConfiguration.HealthCheckUrl)
+	}
+
+	checkSemaphore := semaphore.NewWeighted(20)
+
+	ticker := time.NewTicker(globalConfiguration.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed := false
+		changedLock := sync.Mutex{}
+		wg := sync.WaitGroup{}
+
+		for _, client := range proxyPool.all() {
+			// quarantined proxies are probed by the quarantine manager
+			if client.quarantineState() != QuarantineStateActive {
+				continue
+			}
+
+			err := checkSemaphore.Acquire(ctx, 1)
+			if err != nil {
+				return
+			}
+
+			wg.Add(1)
+			go func(client *ProxyClient) {
+				defer wg.Done()
+				defer checkSemaphore.Release(1)
+
+				// every check gets its own copy of the URL because requests rewrite its scheme
+				checkUri := *uri
+				if client.checkHealth(ctx, &checkUri) {
+					changedLock.Lock()
+					changed = true
+					changedLock.Unlock()
+				}
+			}(client)
+		}
+
+		wg.Wait()
+
+		if changed {
+			proxyPool.lock.Lock()
+			proxyPool.publish()
+			proxyPool.lock.Unlock()
+		}
+	}
+}
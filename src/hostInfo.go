@@ -1,16 +1,48 @@
 package main
 
 import (
+	"crypto/tls"
 	"github.com/ReneKroon/ttlcache"
 	"golang.org/x/net/http2"
+	"golang.org/x/net/proxy"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
 var hostCache = ttlcache.NewCache()
 
+// hosts of hostCache so that they can be saved to the state file, ttlcache is not able to list its items
+var knownHosts = struct {
+	lock  sync.Mutex
+	hosts map[string]*HostInfo
+}{hosts: make(map[string]*HostInfo)}
+
+// host info requests leave through the proxy chain like all other traffic when PROXY_CHAIN is set
+var chainedHostInfoTransport = &http.Transport{
+	Dial: func(network, addr string) (net.Conn, error) {
+		return proxyChain.Dial(network, addr)
+	},
+}
+
+func dialChainedTls(network, addr string, config *tls.Config) (net.Conn, error) {
+	conn, err := proxyChain.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConn := tls.Client(conn, config)
+	err = tlsConn.Handshake()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
 type HostInfo struct {
 	host          string
 	supportsHttp  bool
@@ -18,6 +50,7 @@ type HostInfo struct {
 	supportsH2    bool
 	supportsIPv4  bool
 	supportsIPv6  bool
+	expiresAt     time.Time
 }
 
 func fetchHostInfo(host string) *HostInfo {
@@ -48,6 +81,9 @@ func fetchHostInfo(host string) *HostInfo {
 	}
 
 	client := &http.Client{Timeout: globalConfiguration.HostInfoRequestTimeout}
+	if proxyChain != proxy.Direct {
+		client.Transport = chainedHostInfoTransport
+	}
 	resp, err := client.Get("http://" + host)
 	info.supportsHttp = err == nil && resp.StatusCode != 426
 
@@ -64,6 +100,9 @@ func fetchHostInfo(host string) *HostInfo {
 	info.supportsHttps = err == nil
 
 	transport := &http2.Transport{}
+	if proxyChain != proxy.Direct {
+		transport.DialTLS = dialChainedTls
+	}
 	client = &http.Client{Transport: transport, Timeout: globalConfiguration.HostInfoRequestTimeout}
 	h2Url := "https://" + host
 	if !info.supportsHttps {
@@ -80,15 +119,44 @@ func (info HostInfo) isOnline() bool {
 	return (info.supportsIPv4 || info.supportsIPv6) && (info.supportsHttp || info.supportsH2 || info.supportsHttps)
 }
 
+func setHostInfo(info *HostInfo, ttl time.Duration) {
+	info.expiresAt = time.Now().Add(ttl)
+	hostCache.SetWithTTL(info.host, info, ttl)
+
+	knownHosts.lock.Lock()
+	knownHosts.hosts[info.host] = info
+	knownHosts.lock.Unlock()
+}
+
+// knownHostInfos returns hosts that are still cached
+func knownHostInfos() map[string]*HostInfo {
+	now := time.Now()
+
+	knownHosts.lock.Lock()
+	defer knownHosts.lock.Unlock()
+
+	hosts := make(map[string]*HostInfo, len(knownHosts.hosts))
+	for host, info := range knownHosts.hosts {
+		if now.After(info.expiresAt) {
+			delete(knownHosts.hosts, host)
+			continue
+		}
+
+		hosts[host] = info
+	}
+
+	return hosts
+}
+
 func getHostInfo(host string) *HostInfo {
 	cachedInfo, exists := hostCache.Get(host)
 	if !exists {
 		info := fetchHostInfo(host)
 
 		if info.isOnline() {
-			hostCache.SetWithTTL(host, info, 60*time.Minute)
+			setHostInfo(info, 60*time.Minute)
 		} else {
-			hostCache.SetWithTTL(host, info, 10*time.Second)
+			setHostInfo(info, 10*time.Second)
 		}
 
 		return info
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hostPattern matches target hosts by exact name, `*.example.com` for any subdomain, `*` for every host or `/regex/`
+type hostPattern struct {
+	pattern string
+	regex   *regexp.Regexp
+}
+
+func parseHostPattern(pattern string) (hostPattern, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return hostPattern{}, fmt.Errorf("empty host pattern")
+	}
+
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		regex, err := regexp.Compile("(?i)" + pattern[1:len(pattern)-1])
+		if err != nil {
+			return hostPattern{}, fmt.Errorf("invalid host pattern %s: %w", pattern, err)
+		}
+
+		return hostPattern{pattern: pattern, regex: regex}, nil
+	}
+
+	pattern = strings.ToLower(pattern)
+	if strings.Contains(strings.TrimPrefix(pattern, "*."), "*") && pattern != "*" {
+		return hostPattern{}, fmt.Errorf("invalid host pattern %s, wildcards are only allowed as *.domain or *", pattern)
+	}
+
+	return hostPattern{pattern: pattern}, nil
+}
+
+func (pattern hostPattern) matches(host string) bool {
+	if pattern.regex != nil {
+		return pattern.regex.MatchString(host)
+	}
+
+	host = strings.ToLower(host)
+
+	if pattern.pattern == "*" {
+		return true
+	}
+
+	if strings.HasPrefix(pattern.pattern, "*.") {
+		return strings.HasSuffix(host, pattern.pattern[1:])
+	}
+
+	return host == pattern.pattern
+}
+
+func (pattern hostPattern) String() string {
+	return pattern.pattern
+}
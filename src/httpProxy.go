@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"github.com/go-httpproxy/httpproxy"
 	"io"
 	"log"
@@ -67,7 +68,48 @@ func OnRequest(ctx *httpproxy.Context, req *http.Request) (
 
 	retryOnCodes := make([]uint16, 0)
 
-	_, respChan, err := initializeRequest(req.URL, priority, retryOnCodes, req.Context())
+	proxyTags := splitHeaderList(req.Header.Get("x-proxy-tags"))
+	preferProxyTags := strings.EqualFold(req.Header.Get("x-proxy-tags-mode"), "prefer")
+	proxyCountries := splitHeaderList(req.Header.Get("x-proxy-country"))
+	sessionId := strings.TrimSpace(req.Header.Get("x-session-id"))
+	disableCookieJar := strings.EqualFold(req.Header.Get("x-cookie-jar"), "off")
+
+	headers := req.Header.Clone()
+	removeHopByHopHeaders(headers)
+	headers.Del("Content-Length")
+	headers.Del("x-proxy-tags")
+	headers.Del("x-proxy-tags-mode")
+	headers.Del("x-proxy-country")
+	headers.Del("x-session-id")
+	headers.Del("x-cookie-jar")
+
+	var body []byte
+	if req.Body != nil {
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			log.Printf("ERROR reading request body %s: %v", req.URL.String(), err)
+			return createStringResp("Proxy error", 500)
+		}
+	}
+
+	_, respChan, err := initializeRequest(RequestOptions{
+		Url:              req.URL,
+		Method:           req.Method,
+		Headers:          headers,
+		Body:             body,
+		Priority:         priority,
+		RetryOnCodes:     retryOnCodes,
+		Stream:           true,
+		ProxyTags:        proxyTags,
+		PreferProxyTags:  preferProxyTags,
+		ProxyCountries:   proxyCountries,
+		SessionId:        sessionId,
+		DisableCookieJar: disableCookieJar,
+	}, req.Context())
+	if errors.Is(err, errInvalidMethod) {
+		return createStringResp("Method not allowed", 405)
+	}
+
 	if err != nil {
 		log.Printf("ERROR %s: %v", req.URL.String(), err)
 		return createStringResp("Proxy error", 500)
@@ -86,14 +128,18 @@ func OnRequest(ctx *httpproxy.Context, req *http.Request) (
 		return createStringResp("Remote host unreachable", 502)
 	}
 
-	reader := bytes.NewReader(proxiedResp.Body)
+	// the body is passed through in chunks as it is being downloaded
+	respBody := proxiedResp.BodyStream
+	if respBody == nil {
+		respBody = io.NopCloser(bytes.NewReader(proxiedResp.Body))
+	}
 
 	response := http.Response{
-		Body:             io.NopCloser(reader),
+		Body:             respBody,
 		StatusCode:       proxiedResp.Code,
 		TransferEncoding: nil,
 		Uncompressed:     true,
-		ContentLength:    int64(len(proxiedResp.Body)),
+		ContentLength:    -1,
 		Close:            false,
 		Header:           http.Header{},
 	}
@@ -104,10 +150,14 @@ func OnRequest(ctx *httpproxy.Context, req *http.Request) (
 		}
 
 		for _, value := range values {
-			response.Header.Set(key, value)
+			response.Header.Add(key, value)
 		}
 	}
 
+	if proxiedResp.SessionChanged {
+		response.Header.Set("x-session-changed", "true")
+	}
+
 	response.ContentLength = -1
 	response.TransferEncoding = nil
 
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// expired keys are swept after this many writes
+const limiterStoreSweepEvery = 4096
+
+type limiterEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// LimiterStore is an in-memory throttled.GCRAStore honoring TTLs whose content can be saved to the state file
+type LimiterStore struct {
+	lock    sync.Mutex
+	entries map[string]limiterEntry
+	writes  int
+}
+
+func newLimiterStore() *LimiterStore {
+	return &LimiterStore{
+		entries: make(map[string]limiterEntry),
+	}
+}
+
+func (store *LimiterStore) GetWithTime(key string) (int64, time.Time, error) {
+	now := time.Now()
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entry, ok := store.entries[key]
+	if !ok || now.After(entry.expiresAt) {
+		return -1, now, nil
+	}
+
+	return entry.value, now, nil
+}
+
+func (store *LimiterStore) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if entry, ok := store.entries[key]; ok && !now.After(entry.expiresAt) {
+		return false, nil
+	}
+
+	store.set(key, value, now.Add(ttl))
+
+	return true, nil
+}
+
+func (store *LimiterStore) CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entry, ok := store.entries[key]
+	if !ok || now.After(entry.expiresAt) || entry.value != old {
+		return false, nil
+	}
+
+	store.set(key, new, now.Add(ttl))
+
+	return true, nil
+}
+
+// set stores the value and occasionally sweeps expired keys, store lock must be held
+func (store *LimiterStore) set(key string, value int64, expiresAt time.Time) {
+	store.entries[key] = limiterEntry{value: value, expiresAt: expiresAt}
+
+	store.writes++
+	if store.writes%limiterStoreSweepEvery == 0 {
+		now := time.Now()
+		for key, entry := range store.entries {
+			if now.After(entry.expiresAt) {
+				delete(store.entries, key)
+			}
+		}
+	}
+}
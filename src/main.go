@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"github.com/inhies/go-bytesize"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"log"
@@ -12,16 +13,61 @@ import (
 )
 
 type GlobalConfiguration struct {
-	ProxyListUrl           string        `split_words:"true" required:"true"`
-	RequestTimeout         time.Duration `split_words:"true" default:"20s"`
-	RetryTimeout           time.Duration `split_words:"true" default:"5s"`
-	InitialIpInfoTimeout   time.Duration `split_words:"true" default:"10s"`
-	Retries                int           `split_words:"true" default:"1"`
-	HostInfoRequestTimeout time.Duration `split_words:"true" default:"5s"`
-	ThrottleRequestsPerMin int           `split_words:"true" default:"30"`
-	ThrottleRequestsBurst  int           `split_words:"true" default:"5"`
-	UnreachableClientRetry time.Duration `split_words:"true" default:"60s"`
-	EnableWeb              bool          `split_words:"true" default:"false"`
+	ProxyListUrl                 []string                     `split_words:"true"`
+	ProxyList                    string                       `split_words:"true"`
+	ProxyListFilePollInterval    time.Duration                `split_words:"true" default:"5s"`
+	ProxySourceTags              map[string]string            `split_words:"true"`
+	ProxyType                    string                       `split_words:"true" default:"socks5"`
+	ProxyTlsSkipVerify           bool                         `split_words:"true" default:"false"`
+	ProxyChain                   []string                     `split_words:"true"`
+	GatewaySessions              int                          `split_words:"true" default:"10"`
+	GatewayRotateOn              []int                        `split_words:"true" default:"403,429"`
+	ProxyListRefreshInterval     time.Duration                `split_words:"true" default:"0"`
+	EnableDirect                 bool                         `split_words:"true" default:"false"`
+	DirectSourceIps              []string                     `split_words:"true"`
+	DirectWeight                 float64                      `split_words:"true" default:"1"`
+	RequestTimeout               time.Duration                `split_words:"true" default:"20s"`
+	RetryTimeout                 time.Duration                `split_words:"true" default:"5s"`
+	InitialIpInfoTimeout         time.Duration                `split_words:"true" default:"10s"`
+	Retries                      int                          `split_words:"true" default:"1"`
+	HostInfoRequestTimeout       time.Duration                `split_words:"true" default:"5s"`
+	ThrottleRequestsPerMin       int                          `split_words:"true" default:"30"`
+	ThrottleRequestsBurst        int                          `split_words:"true" default:"5"`
+	GlobalThrottleRequestsPerMin int                          `split_words:"true" default:"0"`
+	GlobalThrottleRequestsBurst  int                          `split_words:"true" default:"5"`
+	RateLimitRulesFile           string                       `split_words:"true"`
+	RateLimitRulesPollInterval   time.Duration                `split_words:"true" default:"5s"`
+	ProxyMaxInFlight             int                          `split_words:"true" default:"0"`
+	SourceMaxInFlight            map[string]int               `split_words:"true"`
+	ProxyMaxConnsPerHost         int                          `split_words:"true" default:"6"`
+	ProxyMaxH2ConnsPerHost       int                          `split_words:"true" default:"12"`
+	UnreachableClientRetry       time.Duration                `split_words:"true" default:"60s"`
+	QuarantineMaxBackoff         time.Duration                `split_words:"true" default:"30m"`
+	QuarantineEvictAfter         int                          `split_words:"true" default:"10"`
+	HealthCheckInterval          time.Duration                `split_words:"true" default:"60s"`
+	HealthCheckUrl               string                       `split_words:"true" default:"https://ifconfig.io/ip"`
+	IpEchoUrl                    string                       `split_words:"true" default:"https://ifconfig.io/ip"`
+	HealthCheckTimeout           time.Duration                `split_words:"true" default:"10s"`
+	HealthCheckFailures          int                          `split_words:"true" default:"3"`
+	HealthCheckSuccesses         int                          `split_words:"true" default:"1"`
+	ProxySelectionStrategy       string                       `split_words:"true" default:"weighted"`
+	GeoipDatabases               []string                     `split_words:"true"`
+	StateFile                    string                       `split_words:"true"`
+	StateSaveInterval            time.Duration                `split_words:"true" default:"1m"`
+	StateEvictedMaxAge           time.Duration                `split_words:"true" default:"24h"`
+	StateMaxAge                  time.Duration                `split_words:"true" default:"10m"`
+	ProxyDailyQuota              bytesize.ByteSize            `split_words:"true"`
+	ProxyMonthlyQuota            bytesize.ByteSize            `split_words:"true"`
+	SourceDailyQuota             map[string]bytesize.ByteSize `split_words:"true"`
+	SourceMonthlyQuota           map[string]bytesize.ByteSize `split_words:"true"`
+	SessionTtl                   time.Duration                `split_words:"true" default:"10m"`
+	EscalationPoliciesFile       string                       `split_words:"true"`
+	EnableCookieJar              bool                         `split_words:"true" default:"false"`
+	CookieJarTtl                 time.Duration                `split_words:"true" default:"30m"`
+	DuplicateExitIps             string                       `split_words:"true" default:"share"`
+	EnableWeb                    bool                         `split_words:"true" default:"false"`
+	ResponseHeaderAllowList      []string                     `split_words:"true"`
+	ResponseHeaderDenyList       []string                     `split_words:"true"`
 }
 
 var globalConfiguration GlobalConfiguration
@@ -37,9 +83,49 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
+	if !isValidProxyType(globalConfiguration.ProxyType) {
+		log.Fatalf("Unsupported PROXY_TYPE %s", globalConfiguration.ProxyType)
+	}
+
+	if !isValidSelectionStrategy(globalConfiguration.ProxySelectionStrategy) {
+		log.Fatalf("Unsupported PROXY_SELECTION_STRATEGY %s", globalConfiguration.ProxySelectionStrategy)
+	}
+
+	if !isValidDuplicateExitIps(globalConfiguration.DuplicateExitIps) {
+		log.Fatalf("Unsupported DUPLICATE_EXIT_IPS %s", globalConfiguration.DuplicateExitIps)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
+	loadGeoDatabases()
+
+	err = loadProxyChain()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = loadEscalationPolicies()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = loadRateLimitRules()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = loadState()
+	if err != nil {
+		log.Fatalf("Error loading state from %s: %v", globalConfiguration.StateFile, err)
+	}
+
 	go runProxyManager(ctx)
+	go runHealthChecker(ctx)
+	go runQuarantineManager(ctx)
+	go runCookieJarCleaner(ctx)
+	go runQuotaChecker(ctx)
+	go runStateSaver(ctx)
+	go runRateLimitRulesWatcher(ctx)
 	go runHttpProxy(ctx)
 	go runGrpcProxy(ctx)
 	go runRequestScheduler(ctx)
@@ -47,11 +133,18 @@ func main() {
 		go runWeb(ctx)
 	}
 
-	c := make(chan os.Signal)
+	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
 
 	log.Printf("Closing")
 
 	cancel()
+
+	if globalConfiguration.StateFile != "" {
+		err := saveState()
+		if err != nil {
+			log.Printf("Error saving state: %v", err)
+		}
+	}
 }
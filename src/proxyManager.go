@@ -1,26 +1,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/dustin/go-broadcast"
 	"github.com/inhies/go-bytesize"
 	"github.com/throttled/throttled"
-	"github.com/throttled/throttled/store/memstore"
 	"golang.org/x/net/http2"
-	"golang.org/x/net/proxy"
-	"golang.org/x/sync/semaphore"
 	"io"
 	"log"
 	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
-	"regexp"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -29,6 +28,66 @@ type ProxyConfig struct {
 	port     int64
 	username string
 	password string
+	// socks5, socks4, socks4a, http, https or direct
+	proxyType string
+	country   string
+	// includes the source name and tags of the source
+	tags []string
+	// name of the proxy list source this entry came from
+	source string
+	// relative share of requests under the random and weighted strategies, zero means 1
+	weight float64
+	// number of virtual clients of a gateway entry, zero means GATEWAY_SESSIONS
+	sessions int
+	// maximum concurrent requests through the proxy, zero means the source or global default
+	maxInFlight int
+	// virtual client of a gateway entry, starting at 1
+	gatewaySlot int
+}
+
+// key identifies the same proxy entry across proxy list downloads, a changed password makes it a new entry
+func (config ProxyConfig) key() string {
+	if config.gatewaySlot > 0 {
+		return fmt.Sprintf("%s://%s:%d:%s:%s#%d", config.proxyType, config.host, config.port, config.username, config.passwordHash(), config.gatewaySlot)
+	}
+
+	return fmt.Sprintf("%s://%s:%d:%s:%s", config.proxyType, config.host, config.port, config.username, config.passwordHash())
+}
+
+// passwordHash tells passwords apart in keys without revealing them in the state file
+func (config ProxyConfig) passwordHash() string {
+	if config.password == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(config.password))
+
+	return hex.EncodeToString(hash[:8])
+}
+
+// address is how the proxy is shown in logs and on the dashboard
+func (config ProxyConfig) address() string {
+	if config.proxyType == proxyTypeDirect {
+		if config.host == "" {
+			return "direct"
+		}
+
+		return fmt.Sprintf("direct from %s", config.host)
+	}
+
+	if config.gatewaySlot > 0 {
+		return fmt.Sprintf("%s:%d #%d", config.host, config.port, config.gatewaySlot)
+	}
+
+	return fmt.Sprintf("%s:%d", config.host, config.port)
+}
+
+func (config ProxyConfig) selectionWeight() float64 {
+	if config.weight <= 0 {
+		return 1
+	}
+
+	return config.weight
 }
 
 func (client *ProxyClient) handleError(req *ActiveRequest, uri *url.URL, mainCtx context.Context, err error) (*Response, error) {
@@ -43,11 +102,11 @@ func (client *ProxyClient) handleError(req *ActiveRequest, uri *url.URL, mainCtx
 	}
 
 	var e *net.OpError
-	if errors.As(err, &e) && e.Op == "socks connect" {
-		log.Printf("%s %s %s %s", client.id, req.Method, uri.String(), "Proxy unreachable")
+	if errors.As(err, &e) && (e.Op == "socks connect" || e.Op == proxyConnectOp) {
+		log.Printf("%s %s %s %s", client.id(), req.Method, uri.String(), "Proxy unreachable")
 		return &Response{Status: ResponseStatusProxyUnreachable}, nil
 	} else {
-		log.Printf("%s %s %s %v", client.id, req.Method, uri.String(), err.Error())
+		log.Printf("%s %s %s %v", client.id(), req.Method, uri.String(), err.Error())
 		return nil, err
 	}
 }
@@ -55,8 +114,17 @@ func (client *ProxyClient) handleError(req *ActiveRequest, uri *url.URL, mainCtx
 func (client *ProxyClient) makeRequestWithClient(req *ActiveRequest, timeout time.Duration) (*Response, error) {
 	start := time.Now()
 
-	requestCtx, cancelFn := context.WithTimeout(req.Context, timeout)
-	defer cancelFn()
+	var requestCtx context.Context
+	var cancelFn context.CancelFunc
+	var headerTimer *time.Timer
+	if req.Stream {
+		// a streamed body may take longer than the timeout to download, so the timeout only applies until headers arrive
+		requestCtx, cancelFn = context.WithCancel(req.Context)
+		headerTimer = time.AfterFunc(timeout, cancelFn)
+	} else {
+		requestCtx, cancelFn = context.WithTimeout(req.Context, timeout)
+		defer cancelFn()
+	}
 
 	if req.Host.supportsHttps {
 		req.Url.Scheme = "https"
@@ -64,7 +132,13 @@ func (client *ProxyClient) makeRequestWithClient(req *ActiveRequest, timeout tim
 		req.Url.Scheme = "http"
 	}
 
-	request, err := http.NewRequest(req.Method, req.Url.String(), nil)
+	// a fresh reader is created for every attempt so that retries send the whole body again
+	var body io.Reader
+	if req.Body != nil {
+		body = bytes.NewReader(req.Body)
+	}
+
+	request, err := http.NewRequest(req.Method, req.Url.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -75,6 +149,14 @@ func (client *ProxyClient) makeRequestWithClient(req *ActiveRequest, timeout tim
 		}
 	}
 
+	// headers sent by the client override the fake ones
+	for key, values := range req.Headers {
+		request.Header.Del(key)
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
 	request = request.WithContext(requestCtx)
 
 	httpClient := client.httpClient
@@ -82,8 +164,17 @@ func (client *ProxyClient) makeRequestWithClient(req *ActiveRequest, timeout tim
 		httpClient = client.http2Client
 	}
 
+	httpClient.Jar = client.cookieJar(req)
+
+	if req.Stream {
+		httpClient.Timeout = 0
+		resp, err := httpClient.Do(request)
+		return client.streamResponse(req, start, requestSize(request, req.Body), resp, err, headerTimer, cancelFn)
+	}
+
 	resp, err := httpClient.Do(request)
 	if err != nil {
+		client.recordBandwidth(req.Url.Hostname(), 0, requestSize(request, req.Body), true)
 		return client.handleError(req, req.Url, req.Context, err)
 	}
 	defer func(Body io.ReadCloser) {
@@ -93,36 +184,64 @@ func (client *ProxyClient) makeRequestWithClient(req *ActiveRequest, timeout tim
 		}
 	}(resp.Body)
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
+	client.recordBandwidth(req.Url.Hostname(), responseSize(resp, int64(len(respBody))), requestSize(request, req.Body), err != nil)
 	if err != nil {
 		return client.handleError(req, req.Url, req.Context, err)
 	}
 
 	duration := time.Since(start)
-	log.Printf("%dp %s %s %s %d %s, %dms", req.Priority, client.id, req.Method, req.Url.String(), resp.StatusCode, bytesize.New(float64(len(body))), duration.Milliseconds())
+	log.Printf("%dp %s %s %s %d %s, %dms", req.Priority, client.id(), req.Method, req.Url.String(), resp.StatusCode, bytesize.New(float64(len(respBody))), duration.Milliseconds())
 
 	mainResponse := Response{
 		Status:  ResponseStatusOk,
 		Code:    resp.StatusCode,
-		Body:    body,
-		Headers: http.Header{},
+		Body:    respBody,
+		Headers: filterResponseHeaders(resp.Header),
 	}
 
-	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
-		mainResponse.Headers.Set("Content-Type", contentType)
+	return &mainResponse, nil
+}
+
+// streamResponse returns the response as soon as its headers are received, the body is read by the client
+func (client *ProxyClient) streamResponse(req *ActiveRequest, start time.Time, sent int64, resp *http.Response, err error, headerTimer *time.Timer, cancelFn context.CancelFunc) (*Response, error) {
+	if !headerTimer.Stop() {
+		cancelFn()
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
+		client.recordBandwidth(req.Url.Hostname(), 0, sent, true)
+
+		if req.Context.Err() != nil {
+			return nil, req.Context.Err()
+		}
+
+		return &Response{
+			Status: ResponseStatusTimeout,
+		}, nil
 	}
 
-	if contentType := resp.Header.Get("Content-Disposition"); contentType != "" {
-		mainResponse.Headers.Set("Content-Disposition", contentType)
+	if err != nil {
+		cancelFn()
+		client.recordBandwidth(req.Url.Hostname(), 0, sent, true)
+		return client.handleError(req, req.Url, req.Context, err)
 	}
 
-	return &mainResponse, nil
-}
+	body := newStreamBody(resp.Body, func(read int64) {
+		cancelFn()
+		client.recordBandwidth(req.Url.Hostname(), responseSize(resp, read), sent, false)
+
+		duration := time.Since(start)
+		log.Printf("%dp %s %s %s %d %s streamed, %dms", req.Priority, client.id(), req.Method, req.Url.String(), resp.StatusCode, bytesize.New(float64(read)), duration.Milliseconds())
+	})
 
-func (client *ProxyClient) markUnreachable() {
-	log.Printf("Marking client %s unrachable", client.id)
-	now := time.Now()
-	client.lastUnreachableAt = &now
+	return &Response{
+		Status:     ResponseStatusOk,
+		Code:       resp.StatusCode,
+		Headers:    filterResponseHeaders(resp.Header),
+		BodyStream: body,
+	}, nil
 }
 
 func getFakeHeaders() http.Header {
@@ -178,82 +297,65 @@ func getFakeHeaders() http.Header {
 	return headers
 }
 
-var proxyRegex = regexp.MustCompile(`(\d{1,3}\.\d{1,3}.\d{1,3}.\d{1,3}):(\d{1,5}):(.+?):(.+)(\r\n)`)
-
-func getProxiesFromUrl(url string) ([]ProxyConfig, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Printf("Error closing body in getProxiesFromUrl: %v", err)
-		}
-	}(resp.Body)
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	matches := proxyRegex.FindAllStringSubmatch(string(body), -1)
+type ProxyClient struct {
+	// current exit IP as shown in logs, the host until it is detected
+	currentId atomic.Pointer[string]
+	// tags of the proxy as listed by the last refresh of its source, config.tags are the ones it was connected with
+	currentTags atomic.Pointer[[]string]
+	config      ProxyConfig
+	httpClient  http.Client
+	http2Client http.Client
+	headers     http.Header
+	// current exit IP, shared by all proxies exiting through it
+	exit atomic.Pointer[ExitIp]
+	// number of requests currently executed through this proxy
+	inFlight   int64
+	health     ProxyHealth
+	stats      ProxyStats
+	quarantine ProxyQuarantine
+	bandwidth  BandwidthUsage
+	// daily or monthly bandwidth quota of the proxy or its source is used up
+	quotaExhausted atomic.Bool
+	// current session of gateway clients, nil for other proxies
+	gateway  atomic.Pointer[GatewaySession]
+	rotating atomic.Bool
+	// when the exit IP was detected
+	connectedAt time.Time
+}
 
-	res := make([]ProxyConfig, 0)
-	for _, v := range matches {
-		port, err := strconv.ParseInt(v[2], 10, 32)
-		if err != nil {
-			return nil, err
-		}
+func (client *ProxyClient) id() string {
+	return *client.currentId.Load()
+}
 
-		res = append(res, ProxyConfig{
-			host:     v[1],
-			port:     port,
-			username: v[3],
-			password: v[4],
-		})
-	}
+func (client *ProxyClient) setId(id string) {
+	client.currentId.Store(&id)
+}
 
-	return res, nil
+func (client *ProxyClient) tags() []string {
+	return *client.currentTags.Load()
 }
 
-type ProxyClient struct {
-	id                string
-	httpClient        http.Client
-	http2Client       http.Client
-	headers           http.Header
-	limiter           *throttled.GCRARateLimiter
-	lastUnreachableAt *time.Time
+func (client *ProxyClient) setTags(tags []string) {
+	client.currentTags.Store(&tags)
 }
 
 func (client *ProxyClient) RateLimit(host HostInfo) (bool, throttled.RateLimitResult, error) {
-	if client.lastUnreachableAt != nil && client.lastUnreachableAt.Add(globalConfiguration.UnreachableClientRetry).Before(time.Now()) {
-		return false, throttled.RateLimitResult{
-			RetryAfter: time.Now().Sub(client.lastUnreachableAt.Add(globalConfiguration.UnreachableClientRetry)),
-		}, nil
+	limiter := client.exitIp().limiterFor(host.host)
+	if limiter == nil {
+		return false, throttled.RateLimitResult{Limit: -1, Remaining: -1, ResetAfter: -1, RetryAfter: -1}, nil
 	}
 
-	limited, result, err := client.limiter.RateLimit(host.host, 0)
+	limited, result, err := limiter.RateLimit(host.host, 0)
 	if limited {
 		return limited, result, err
 	}
 
-	limited, result, err = client.limiter.RateLimit(host.host, 1)
+	limited, result, err = limiter.RateLimit(host.host, 1)
 
 	return limited, result, err
 }
 
-func createLimiter() *throttled.GCRARateLimiter {
-	store, err := memstore.New(65536)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	quota := throttled.RateQuota{
-		MaxRate:  throttled.PerMin(globalConfiguration.ThrottleRequestsPerMin),
-		MaxBurst: globalConfiguration.ThrottleRequestsBurst,
-	}
-
+func createLimiter(store throttled.GCRAStore, quota throttled.RateQuota) *throttled.GCRARateLimiter {
 	rateLimiter, err := throttled.NewGCRARateLimiter(store, quota)
 	if err != nil {
 		log.Fatal(err)
@@ -262,114 +364,194 @@ func createLimiter() *throttled.GCRARateLimiter {
 	return rateLimiter
 }
 
-func getExternalProxyIp(client *ProxyClient, ctx context.Context) (*string, error) {
-	uri, _ := url.Parse("https://ifconfig.io/ip")
-	ipConfigHostInfo := getHostInfo("ifconfig.io")
+// probe makes a GET request to uri through the proxy outside the request queue
+func (client *ProxyClient) probe(ctx context.Context, uri *url.URL, timeout time.Duration) (*Response, error) {
+	hostInfo := getHostInfo(uri.Hostname())
 	req := &ActiveRequest{
 		Id:       0,
 		Url:      uri,
-		Method:   "GET",
+		Method:   http.MethodGet,
+		Headers:  http.Header{},
 		Priority: 10000,
-		Host:     *ipConfigHostInfo,
+		Host:     *hostInfo,
 		Context:  ctx,
 		Callback: nil,
 		Lock:     sync.Mutex{},
+		// probes must not pick up cookies of real requests
+		DisableCookieJar: true,
 	}
-	ipResp, err := client.makeRequestWithClient(req, globalConfiguration.InitialIpInfoTimeout)
+
+	return client.makeRequestWithClient(req, timeout)
+}
+
+// getExternalProxyIp asks IP_ECHO_URL which IP the proxy exits through
+func getExternalProxyIp(client *ProxyClient, ctx context.Context) (*string, error) {
+	uri, err := url.Parse(globalConfiguration.IpEchoUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	ip := strings.Trim(string(ipResp.Body), "\n")
+	ipResp, err := client.probe(ctx, uri, globalConfiguration.InitialIpInfoTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	ip := strings.TrimSpace(string(ipResp.Body))
+	if ipResp.Status != ResponseStatusOk || net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("%s did not return the exit IP", globalConfiguration.IpEchoUrl)
+	}
 
 	return &ip, nil
 }
 
 var proxyListChangedBroadcaster = broadcast.NewBroadcaster(1)
 
-func runProxyManager(ctx context.Context) {
-	proxies, err := getProxiesFromUrl(globalConfiguration.ProxyListUrl)
-	if err != nil {
-		log.Fatal(err)
+func connectProxy(ctx context.Context, config ProxyConfig) (*ProxyClient, error) {
+	log.Printf("Connecting to %s proxy %s from %s", config.proxyType, config.address(), config.source)
+
+	myClient := &ProxyClient{
+		health:  ProxyHealth{healthy: true},
+		stats:   newProxyStats(),
+		config:  config,
+		headers: getFakeHeaders(),
 	}
+	myClient.setId(config.host)
+	myClient.setTags(config.tags)
 
-	proxyConnSemaphore := semaphore.NewWeighted(20)
+	persisted, restored := restoredProxy(config.key())
 
-	readyProxies := make([]*ProxyClient, 0)
+	var dial func(network, addr string) (net.Conn, error)
+	if config.isGateway() {
+		sessionId := newGatewaySessionId()
+		if restored && persisted.GatewaySession != "" {
+			sessionId = persisted.GatewaySession
+		}
 
-	for _, config := range proxies {
-		err := proxyConnSemaphore.Acquire(ctx, 1)
+		err := myClient.newGatewaySession(sessionId)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 
-		go func(config ProxyConfig) {
-			defer proxyConnSemaphore.Release(1)
+		dial = myClient.dialGateway
+	} else {
+		dialProxy, err := createProxyDialer(config, proxyChain)
+		if err != nil {
+			return nil, err
+		}
 
-			log.Printf("Connecting to proxy %s:%d", config.host, config.port)
-			dialSocksProxy, err := proxy.SOCKS5(
-				"tcp",
-				fmt.Sprintf("%s:%d", config.host, config.port),
-				&proxy.Auth{User: config.username, Password: config.password},
-				proxy.Direct,
-			)
+		dial = dialProxy.Dial
+	}
 
-			if err != nil {
-				log.Printf("Error connecting to proxy: %v", err)
-				return
-			}
-			http2Transport := &http.Transport{
-				Dial:            dialSocksProxy.Dial,
-				MaxIdleConns:    1024,
-				MaxConnsPerHost: 12,
-				IdleConnTimeout: 60 * time.Second,
-			}
+	http2Transport := &http.Transport{
+		Dial:            dial,
+		MaxIdleConns:    1024,
+		MaxConnsPerHost: globalConfiguration.ProxyMaxH2ConnsPerHost,
+		IdleConnTimeout: 60 * time.Second,
+	}
 
-			err = http2.ConfigureTransport(http2Transport)
-			if err != nil {
-				log.Printf("error upgrading proxy to http2: %v", err)
-				return
-			}
+	err := http2.ConfigureTransport(http2Transport)
+	if err != nil {
+		return nil, fmt.Errorf("error upgrading proxy to http2: %w", err)
+	}
 
-			http2Client := http.Client{
-				Transport: http2Transport,
-				Timeout:   time.Second * 10,
-			}
+	myClient.http2Client = http.Client{
+		Transport: http2Transport,
+		Timeout:   time.Second * 10,
+	}
 
-			httpClient := http.Client{
-				Transport: &http.Transport{
-					Dial:            dialSocksProxy.Dial,
-					MaxIdleConns:    1024,
-					MaxConnsPerHost: 6,
-					IdleConnTimeout: 60 * time.Second,
-				},
-				Timeout: time.Second * 10,
-			}
+	myClient.httpClient = http.Client{
+		Transport: &http.Transport{
+			Dial:            dial,
+			MaxIdleConns:    1024,
+			MaxConnsPerHost: globalConfiguration.ProxyMaxConnsPerHost,
+			IdleConnTimeout: 60 * time.Second,
+		},
+		Timeout: time.Second * 10,
+	}
 
-			myClient := ProxyClient{
-				id:          config.host,
-				httpClient:  httpClient,
-				http2Client: http2Client,
-				headers:     getFakeHeaders(),
-				limiter:     createLimiter(),
-			}
+	if restored {
+		myClient.restore(persisted)
+	}
 
-			ip, err := getExternalProxyIp(&myClient, ctx)
-			if err != nil {
-				log.Printf("Error connecting to proxy: %v", err)
-				return
-			}
+	// the exit IP of a proxy validated shortly before a restart is trusted without detecting it again
+	if restored && persisted.isFresh() {
+		myClient.setId(persisted.ExitIp)
+		myClient.connectedAt = persisted.ValidatedAt
+
+		log.Printf("Proxy %s from %s restored", myClient.id(), config.source)
+
+		return myClient, nil
+	}
+
+	ip, err := getExternalProxyIp(myClient, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	myClient.setId(*ip)
+	myClient.connectedAt = time.Now()
+
+	log.Printf("Proxy %s from %s ready", *ip, config.source)
+
+	return myClient, nil
+}
+
+func runProxyManager(ctx context.Context) {
+	sources, err := parseProxySources()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = proxyPool.refresh(ctx, sources)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// stays nil when periodic refresh is disabled, files are still watched
+	var refreshTick <-chan time.Time
+	if globalConfiguration.ProxyListRefreshInterval > 0 {
+		refreshTicker := time.NewTicker(globalConfiguration.ProxyListRefreshInterval)
+		defer refreshTicker.Stop()
+		refreshTick = refreshTicker.C
+	}
 
-			myClient.id = *ip
+	fileTicker := time.NewTicker(globalConfiguration.ProxyListFilePollInterval)
+	defer fileTicker.Stop()
 
-			log.Printf("Proxy %s ready", *ip)
+	fileModTimes := make(map[string]time.Time)
+	for _, source := range sources {
+		if modTime, ok := source.modTime(); ok {
+			fileModTimes[source.name] = modTime
+		}
+	}
 
-			readyProxies = append(readyProxies, &myClient)
-			proxiesToSend := make([]*ProxyClient, len(readyProxies))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refreshTick:
+			err := proxyPool.refresh(ctx, sources)
+			if err != nil {
+				log.Printf("Error refreshing proxy list: %v", err)
+			}
+		case <-fileTicker.C:
+			changed := make([]ProxySource, 0)
+			for _, source := range sources {
+				modTime, ok := source.modTime()
+				if ok && !modTime.Equal(fileModTimes[source.name]) {
+					fileModTimes[source.name] = modTime
+					changed = append(changed, source)
+				}
+			}
 
-			copy(proxiesToSend, readyProxies)
+			if len(changed) == 0 {
+				continue
+			}
 
-			proxyListChangedBroadcaster.Submit(proxiesToSend)
-		}(config)
+			err := proxyPool.refresh(ctx, changed)
+			if err != nil {
+				log.Printf("Error refreshing proxy list: %v", err)
+			}
+		}
 	}
 }
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+// ProxyPool holds connected proxies and keeps them in sync with the proxy list
+type ProxyPool struct {
+	lock sync.Mutex
+	// last successfully fetched entries of every source, in the order sources were first seen
+	sourceConfigs map[string][]ProxyConfig
+	sourceOrder   []string
+	// entries of the latest proxy list by ProxyConfig.key
+	configs map[string]ProxyConfig
+	// connected proxies, only the healthy ones are available for scheduling
+	clients map[string]*ProxyClient
+	// proxies that are currently being connected
+	connecting map[string]struct{}
+	// when proxies were evicted by quarantine, they are not connected again even if still listed
+	evicted map[string]time.Time
+	// connected proxies grouped by their exit IP
+	exitIps   map[string]*ExitIp
+	semaphore *semaphore.Weighted
+}
+
+var proxyPool = &ProxyPool{
+	sourceConfigs: make(map[string][]ProxyConfig),
+	configs:       make(map[string]ProxyConfig),
+	clients:       make(map[string]*ProxyClient),
+	connecting:    make(map[string]struct{}),
+	evicted:       make(map[string]time.Time),
+	exitIps:       make(map[string]*ExitIp),
+	semaphore:     semaphore.NewWeighted(20),
+}
+
+// refresh downloads the given proxy sources, connects new proxies and retires the ones that are no longer listed.
+// A source that fails to download keeps its previous entries.
+func (pool *ProxyPool) refresh(ctx context.Context, sources []ProxySource) error {
+	fetched := make(map[string][]ProxyConfig)
+	for _, source := range sources {
+		proxies, err := source.fetch()
+		if err != nil {
+			log.Printf("Error fetching proxy list %s: %v", source.name, err)
+			continue
+		}
+
+		log.Printf("Fetched %d proxies from %s", len(proxies), source.name)
+		fetched[source.name] = proxies
+	}
+
+	if len(fetched) == 0 {
+		return errors.New("failed to fetch any proxy list")
+	}
+
+	pool.lock.Lock()
+	for _, source := range sources {
+		proxies, ok := fetched[source.name]
+		if !ok {
+			continue
+		}
+
+		if _, seen := pool.sourceConfigs[source.name]; !seen {
+			pool.sourceOrder = append(pool.sourceOrder, source.name)
+		}
+
+		pool.sourceConfigs[source.name] = proxies
+	}
+
+	// the same proxy listed by multiple sources is attributed to the first one
+	configs := make(map[string]ProxyConfig)
+	for _, name := range pool.sourceOrder {
+		for _, entry := range pool.sourceConfigs[name] {
+			for _, config := range expandGateway(entry) {
+				if _, ok := configs[config.key()]; !ok {
+					configs[config.key()] = config
+				}
+			}
+		}
+	}
+
+	pool.configs = configs
+
+	removed := make([]*ProxyClient, 0)
+	for key, client := range pool.clients {
+		config, ok := configs[key]
+		if !ok {
+			delete(pool.clients, key)
+			pool.leaveExitIp(client)
+			removed = append(removed, client)
+			continue
+		}
+
+		// tags are not part of the key, so a proxy listed with new tags keeps its client
+		client.setTags(config.tags)
+	}
+
+	added := make([]ProxyConfig, 0)
+	for key, config := range configs {
+		_, connected := pool.clients[key]
+		_, connecting := pool.connecting[key]
+		_, evicted := pool.evicted[key]
+		if !connected && !connecting && !evicted {
+			pool.connecting[key] = struct{}{}
+			added = append(added, config)
+		}
+	}
+
+	if len(removed) > 0 {
+		pool.publish()
+	}
+	pool.lock.Unlock()
+
+	log.Printf("Proxy list refreshed, %d proxies listed, %d added, %d removed", len(configs), len(added), len(removed))
+
+	for _, client := range removed {
+		go client.retire(ctx)
+	}
+
+	for _, config := range added {
+		err := pool.semaphore.Acquire(ctx, 1)
+		if err != nil {
+			return err
+		}
+
+		go func(config ProxyConfig) {
+			defer pool.semaphore.Release(1)
+
+			client, err := connectProxy(ctx, config)
+
+			pool.lock.Lock()
+			defer pool.lock.Unlock()
+
+			delete(pool.connecting, config.key())
+
+			if err != nil {
+				log.Printf("Error connecting to proxy: %v", err)
+				return
+			}
+
+			// the proxy might have been removed from the list or listed with other tags while we were connecting
+			listed, ok := pool.configs[config.key()]
+			if !ok {
+				go client.retire(ctx)
+				return
+			}
+			client.setTags(listed.tags)
+
+			if !pool.joinExitIp(client, client.id()) {
+				log.Printf("Proxy %s exits through %s used by another proxy, dropping it", config.address(), client.id())
+				go client.retire(ctx)
+				return
+			}
+
+			pool.clients[config.key()] = client
+			pool.publish()
+		}(config)
+	}
+
+	return nil
+}
+
+// evict removes a proxy from the pool for good
+func (pool *ProxyPool) evict(client *ProxyClient) {
+	pool.lock.Lock()
+	key := client.config.key()
+	if pool.clients[key] == client {
+		delete(pool.clients, key)
+		pool.leaveExitIp(client)
+		pool.evicted[key] = time.Now()
+		pool.publish()
+	}
+	pool.lock.Unlock()
+
+	go client.retire(context.Background())
+}
+
+// publish sends the current set of healthy and active proxies within their quota to the scheduler, pool lock must be held
+func (pool *ProxyPool) publish() {
+	proxiesToSend := make([]*ProxyClient, 0, len(pool.clients))
+	for _, client := range pool.clients {
+		if client.isHealthy() && client.quarantineState() == QuarantineStateActive && !client.quotaExhausted.Load() {
+			proxiesToSend = append(proxiesToSend, client)
+		}
+	}
+
+	proxyListChangedBroadcaster.Submit(proxiesToSend)
+}
+
+// all returns every connected proxy including the unhealthy ones
+func (pool *ProxyPool) all() []*ProxyClient {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	clients := make([]*ProxyClient, 0, len(pool.clients))
+	for _, client := range pool.clients {
+		clients = append(clients, client)
+	}
+
+	return clients
+}
+
+// snapshot returns every connected proxy sorted by source and id
+func (pool *ProxyPool) snapshot() []*ProxyClient {
+	clients := pool.all()
+
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].config.source != clients[j].config.source {
+			return clients[i].config.source < clients[j].config.source
+		}
+
+		return clients[i].id() < clients[j].id()
+	})
+
+	return clients
+}
+
+// retire waits for in-flight requests of a proxy removed from the pool to finish and closes its connections
+func (client *ProxyClient) retire(ctx context.Context) {
+	log.Printf("Retiring proxy %s", client.id())
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for atomic.LoadInt64(&client.inFlight) > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	client.httpClient.CloseIdleConnections()
+	client.http2Client.CloseIdleConnections()
+
+	log.Printf("Proxy %s retired", client.id())
+}
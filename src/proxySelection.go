@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// weight of the newest sample in the moving averages of ProxyStats
+const statsAlpha = 0.1
+
+// ProxyStats are exponentially weighted moving averages of request outcomes through a proxy
+type ProxyStats struct {
+	lock                sync.Mutex
+	successRate         float64
+	timeoutRate         float64
+	tooManyRequestsRate float64
+	// milliseconds until response headers, zero until the first successful request
+	latency float64
+}
+
+func newProxyStats() ProxyStats {
+	return ProxyStats{
+		successRate: 1,
+	}
+}
+
+func ewma(average float64, sample float64) float64 {
+	return average + statsAlpha*(sample-average)
+}
+
+func boolSample(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// record adds the outcome of a request made through the proxy, cancelled requests are ignored
+func (stats *ProxyStats) record(resp *Response, err error, latency time.Duration) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	timeout := err == nil && resp.Status == ResponseStatusTimeout
+	tooManyRequests := err == nil && resp.Status == ResponseStatusOk && resp.Code == 429
+	success := err == nil && resp.Status == ResponseStatusOk && resp.Code != 0 && resp.Code != 502 && !tooManyRequests
+
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+
+	stats.successRate = ewma(stats.successRate, boolSample(success))
+	stats.timeoutRate = ewma(stats.timeoutRate, boolSample(timeout))
+	stats.tooManyRequestsRate = ewma(stats.tooManyRequestsRate, boolSample(tooManyRequests))
+
+	if success {
+		ms := float64(latency.Milliseconds())
+		if stats.latency == 0 {
+			stats.latency = ms
+		} else {
+			stats.latency = ewma(stats.latency, ms)
+		}
+	}
+}
+
+// score is higher for proxies that succeed often and respond quickly, it is always positive
+func (stats *ProxyStats) score() float64 {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+
+	score := stats.successRate * (1 - stats.timeoutRate/2) * (1 - stats.tooManyRequestsRate/2) / (1 + stats.latency/1000)
+
+	return math.Max(score, 0.01)
+}
+
+func (stats *ProxyStats) latencyMs() float64 {
+	stats.lock.Lock()
+	defer stats.lock.Unlock()
+
+	return stats.latency
+}
+
+var selectionStrategies = map[string]func(proxies []*ProxyClient) []int{
+	"random":          selectRandom,
+	"weighted":        selectWeighted,
+	"least-latency":   selectLeastLatency,
+	"least-in-flight": selectLeastInFlight,
+	"round-robin":     selectRoundRobin,
+}
+
+func isValidSelectionStrategy(strategy string) bool {
+	_, ok := selectionStrategies[strategy]
+	return ok
+}
+
+// orderProxies returns indices of proxies in the order in which the scheduler should try them
+func orderProxies(proxies []*ProxyClient) []int {
+	return selectionStrategies[globalConfiguration.ProxySelectionStrategy](proxies)
+}
+
+// hasTags reports whether the proxy has all the given tags
+func (client *ProxyClient) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, clientTag := range client.tags() {
+			if strings.EqualFold(tag, clientTag) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func orderedProxies(proxies []*ProxyClient) []*ProxyClient {
+	ordered := make([]*ProxyClient, 0, len(proxies))
+	for _, idx := range orderProxies(proxies) {
+		ordered = append(ordered, proxies[idx])
+	}
+
+	return ordered
+}
+
+// candidateProxies returns proxies the request may be executed at in the order they should be tried
+func (request *ActiveRequest) candidateProxies(proxies []*ProxyClient) []*ProxyClient {
+	// a pinned session waits for its proxy even when it is rate limited, unless the request escalated past its tier
+	if pinned := request.pinnedProxy(proxies); pinned != nil && pinned.hasTags(request.tierTags()) {
+		// until a rotating gateway client learns its new exit IP it is unknown whether the session keeps its IP
+		if pinned.rotating.Load() {
+			return nil
+		}
+
+		return []*ProxyClient{pinned}
+	}
+
+	// gateway clients are skipped while they rotate, their requests would leave through an unknown exit IP
+	available := make([]*ProxyClient, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !proxy.rotating.Load() {
+			available = append(available, proxy)
+		}
+	}
+	proxies = available
+
+	tags := request.ProxyTags
+	if tierTags := request.tierTags(); len(tierTags) > 0 {
+		tags = append(append([]string{}, tags...), tierTags...)
+	}
+
+	if len(tags) == 0 && len(request.ProxyCountries) == 0 {
+		return orderedProxies(proxies)
+	}
+
+	matching := make([]*ProxyClient, 0, len(proxies))
+	others := make([]*ProxyClient, 0)
+	for _, proxy := range proxies {
+		if !proxy.inCountries(request.ProxyCountries) {
+			continue
+		}
+
+		if proxy.hasTags(tags) {
+			matching = append(matching, proxy)
+		} else {
+			others = append(others, proxy)
+		}
+	}
+
+	candidates := orderedProxies(matching)
+	if request.PreferProxyTags {
+		candidates = append(candidates, orderedProxies(others)...)
+	}
+
+	return candidates
+}
+
+// selectRandom is a weighted random permutation (Efraimidis-Spirakis) with configured proxy weights, uniform unless weights are set
+func selectRandom(proxies []*ProxyClient) []int {
+	keys := make([]float64, len(proxies))
+	for i, proxy := range proxies {
+		keys[i] = math.Pow(rand.Float64(), 1/proxy.config.selectionWeight())
+	}
+
+	indices := shuffleIndices(len(proxies))
+	sort.SliceStable(indices, func(i, j int) bool {
+		return keys[indices[i]] > keys[indices[j]]
+	})
+
+	return indices
+}
+
+// selectWeighted is a weighted random permutation (Efraimidis-Spirakis) with proxy scores times configured weights as weights
+func selectWeighted(proxies []*ProxyClient) []int {
+	keys := make([]float64, len(proxies))
+	for i, proxy := range proxies {
+		keys[i] = math.Pow(rand.Float64(), 1/(proxy.stats.score()*proxy.config.selectionWeight()))
+	}
+
+	indices := shuffleIndices(len(proxies))
+	sort.SliceStable(indices, func(i, j int) bool {
+		return keys[indices[i]] > keys[indices[j]]
+	})
+
+	return indices
+}
+
+func selectLeastLatency(proxies []*ProxyClient) []int {
+	latencies := make([]float64, len(proxies))
+	for i, proxy := range proxies {
+		latencies[i] = proxy.stats.latencyMs()
+	}
+
+	// proxies without measured latency come first so that they get measured
+	indices := shuffleIndices(len(proxies))
+	sort.SliceStable(indices, func(i, j int) bool {
+		return latencies[indices[i]] < latencies[indices[j]]
+	})
+
+	return indices
+}
+
+func selectLeastInFlight(proxies []*ProxyClient) []int {
+	inFlight := make([]int64, len(proxies))
+	for i, proxy := range proxies {
+		inFlight[i] = atomic.LoadInt64(&proxy.inFlight)
+	}
+
+	indices := shuffleIndices(len(proxies))
+	sort.SliceStable(indices, func(i, j int) bool {
+		return inFlight[indices[i]] < inFlight[indices[j]]
+	})
+
+	return indices
+}
+
+var roundRobinCounter uint64
+
+func selectRoundRobin(proxies []*ProxyClient) []int {
+	indices := make([]int, len(proxies))
+	if len(proxies) == 0 {
+		return indices
+	}
+
+	offset := int(atomic.AddUint64(&roundRobinCounter, 1) % uint64(len(proxies)))
+	for i := range indices {
+		indices[i] = (offset + i) % len(proxies)
+	}
+
+	return indices
+}
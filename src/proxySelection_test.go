@@ -0,0 +1,170 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestProxyStatsScore(t *testing.T) {
+	ok := &Response{Status: ResponseStatusOk, Code: 200}
+
+	tests := []struct {
+		name      string
+		responses []*Response
+		latency   time.Duration
+		min       float64
+		max       float64
+	}{
+		{
+			name: "new proxy",
+			min:  1,
+			max:  1,
+		},
+		{
+			name:      "fast successes",
+			responses: []*Response{ok, ok, ok},
+			latency:   100 * time.Millisecond,
+			min:       0.9,
+			max:       0.91,
+		},
+		{
+			name:      "slow successes",
+			responses: []*Response{ok, ok, ok},
+			latency:   2 * time.Second,
+			min:       0.33,
+			max:       0.34,
+		},
+		{
+			name:      "timeouts",
+			responses: []*Response{{Status: ResponseStatusTimeout}, {Status: ResponseStatusTimeout}, {Status: ResponseStatusTimeout}},
+			min:       0.6,
+			max:       0.7,
+		},
+		{
+			name:      "too many requests",
+			responses: []*Response{{Status: ResponseStatusOk, Code: 429}, {Status: ResponseStatusOk, Code: 429}},
+			min:       0.7,
+			max:       0.8,
+		},
+		{
+			name:      "failures only",
+			responses: repeatResponse(&Response{Status: ResponseStatusOk, Code: 502}, 100),
+			min:       0.01,
+			max:       0.01,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stats := newProxyStats()
+			for _, resp := range test.responses {
+				stats.record(resp, nil, test.latency)
+			}
+
+			score := stats.score()
+			if score < test.min || score > test.max {
+				t.Errorf("expected score between %f and %f, got %f", test.min, test.max, score)
+			}
+		})
+	}
+}
+
+func repeatResponse(resp *Response, count int) []*Response {
+	responses := make([]*Response, count)
+	for i := range responses {
+		responses[i] = resp
+	}
+
+	return responses
+}
+
+// slowProxy returns a proxy whose only request took a second, which gives it a score of 0.5
+func slowProxy(config ProxyConfig) *ProxyClient {
+	client := &ProxyClient{config: config, stats: newProxyStats()}
+	client.stats.record(&Response{Status: ResponseStatusOk, Code: 200}, nil, time.Second)
+
+	return client
+}
+
+func TestSelectionStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy func(proxies []*ProxyClient) []int
+		proxies  []*ProxyClient
+		// how often the first proxy is expected to be tried first
+		share float64
+	}{
+		{
+			name:     "random is uniform",
+			strategy: selectRandom,
+			proxies:  []*ProxyClient{{stats: newProxyStats()}, {stats: newProxyStats()}},
+			share:    0.5,
+		},
+		{
+			name:     "random follows configured weights",
+			strategy: selectRandom,
+			proxies:  []*ProxyClient{{config: ProxyConfig{weight: 3}, stats: newProxyStats()}, {stats: newProxyStats()}},
+			share:    0.75,
+		},
+		{
+			name:     "random ignores scores",
+			strategy: selectRandom,
+			proxies:  []*ProxyClient{slowProxy(ProxyConfig{}), {stats: newProxyStats()}},
+			share:    0.5,
+		},
+		{
+			name:     "weighted follows scores",
+			strategy: selectWeighted,
+			proxies:  []*ProxyClient{slowProxy(ProxyConfig{}), {stats: newProxyStats()}},
+			share:    1.0 / 3,
+		},
+		{
+			name:     "weighted follows scores times configured weights",
+			strategy: selectWeighted,
+			proxies:  []*ProxyClient{slowProxy(ProxyConfig{weight: 2}), {stats: newProxyStats()}},
+			share:    0.5,
+		},
+		{
+			name:     "least latency",
+			strategy: selectLeastLatency,
+			proxies:  []*ProxyClient{{stats: newProxyStats()}, slowProxy(ProxyConfig{})},
+			share:    1,
+		},
+		{
+			name:     "least in flight",
+			strategy: selectLeastInFlight,
+			proxies:  []*ProxyClient{{stats: newProxyStats()}, {inFlight: 1, stats: newProxyStats()}},
+			share:    1,
+		},
+		{
+			name:     "round robin",
+			strategy: selectRoundRobin,
+			proxies:  []*ProxyClient{{stats: newProxyStats()}, {stats: newProxyStats()}},
+			share:    0.5,
+		},
+	}
+
+	const rounds = 20000
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			first := 0
+			for i := 0; i < rounds; i++ {
+				indices := test.strategy(test.proxies)
+				if len(indices) != len(test.proxies) {
+					t.Fatalf("expected %d indices, got %v", len(test.proxies), indices)
+				}
+
+				if indices[0] == 0 {
+					first++
+				}
+			}
+
+			share := float64(first) / rounds
+			if math.Abs(share-test.share) > 0.03 {
+				t.Errorf("expected the first proxy first in %.2f of rounds, got %.2f", test.share, share)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ProxySource is one place proxies are loaded from, either a http(s) URL, a file:// path or the PROXY_LIST variable
+type ProxySource struct {
+	name string
+	// tags given to every proxy of this source in addition to the source name
+	tags []string
+	url  *url.URL
+	// proxy list given inline instead of an URL
+	inline string
+	// the source lists direct pool members instead of proxies
+	direct bool
+}
+
+var sourceNameRegex = regexp.MustCompile(`^([\w.-]+)=(.+)$`)
+
+// parseProxySources reads sources from PROXY_LIST_URL entries in the form of `[name=]url` and PROXY_LIST
+func parseProxySources() ([]ProxySource, error) {
+	sources := make([]ProxySource, 0)
+	names := make(map[string]int)
+
+	uniqueName := func(name string) string {
+		names[name]++
+		if names[name] > 1 {
+			return fmt.Sprintf("%s#%d", name, names[name])
+		}
+
+		return name
+	}
+
+	for _, entry := range globalConfiguration.ProxyListUrl {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name := ""
+		if match := sourceNameRegex.FindStringSubmatch(entry); match != nil && !strings.Contains(match[1], ":") {
+			name = match[1]
+			entry = match[2]
+		}
+
+		uri, err := url.Parse(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy list url %s: %w", entry, err)
+		}
+
+		switch uri.Scheme {
+		case "http", "https":
+			if name == "" {
+				name = uri.Hostname()
+			}
+		case "file":
+			if name == "" {
+				name = filepath.Base(uri.Path)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported proxy list url %s", entry)
+		}
+
+		name = uniqueName(name)
+		sources = append(sources, ProxySource{
+			name: name,
+			tags: splitTags(globalConfiguration.ProxySourceTags[name]),
+			url:  uri,
+		})
+	}
+
+	if strings.TrimSpace(globalConfiguration.ProxyList) != "" {
+		name := uniqueName("env")
+		sources = append(sources, ProxySource{
+			name:   name,
+			tags:   splitTags(globalConfiguration.ProxySourceTags[name]),
+			inline: globalConfiguration.ProxyList,
+		})
+	}
+
+	if globalConfiguration.EnableDirect {
+		source, err := directSource(uniqueName(proxyTypeDirect))
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, source)
+	}
+
+	if len(sources) == 0 {
+		return nil, errors.New("no proxy list configured, set PROXY_LIST_URL, PROXY_LIST or ENABLE_DIRECT")
+	}
+
+	return sources, nil
+}
+
+// modTime returns modification time of file sources, other sources are not watched
+func (source ProxySource) modTime() (time.Time, bool) {
+	if source.url == nil || source.url.Scheme != "file" {
+		return time.Time{}, false
+	}
+
+	info, err := os.Stat(source.url.Path)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return info.ModTime(), true
+}
+
+func (source ProxySource) download() (string, error) {
+	if source.url == nil {
+		return inlineProxyList(source.inline), nil
+	}
+
+	if source.url.Scheme == "file" {
+		body, err := os.ReadFile(source.url.Path)
+		return string(body), err
+	}
+
+	resp, err := http.Get(source.url.String())
+	if err != nil {
+		return "", err
+	}
+
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Error closing body in ProxySource.download: %v", err)
+		}
+	}(resp.Body)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("proxy list %s returned %d", source.name, resp.StatusCode)
+	}
+
+	return string(body), nil
+}
+
+// inlineProxyList turns PROXY_LIST into a proxy list, line lists may be whitespace separated to fit on one line
+// while JSON and CSV are kept as they are
+func inlineProxyList(inline string) string {
+	trimmed := strings.TrimSpace(inline)
+	if strings.HasPrefix(trimmed, "[") && json.Valid([]byte(trimmed)) {
+		return inline
+	}
+
+	if isCsvList(trimmed) {
+		return inline
+	}
+
+	entries := make([]string, 0)
+	for _, line := range strings.Split(trimmed, "\n") {
+		// comments run until the end of the line
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		entries = append(entries, strings.Fields(line)...)
+	}
+
+	return strings.Join(entries, "\n")
+}
+
+func (source ProxySource) fetch() ([]ProxyConfig, error) {
+	var proxies []ProxyConfig
+	if source.direct {
+		proxies = directConfigs(source.name)
+	} else {
+		body, err := source.download()
+		if err != nil {
+			return nil, err
+		}
+
+		var errs []error
+		proxies, errs = parseProxyList(body, source.name)
+		for _, err := range errs {
+			log.Printf("Error parsing proxy list %s: %v", source.name, err)
+		}
+	}
+
+	for i := range proxies {
+		proxies[i].tags = append(append([]string{source.name}, source.tags...), proxies[i].tags...)
+	}
+
+	return proxies, nil
+}
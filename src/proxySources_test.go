@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInlineProxyList(t *testing.T) {
+	globalConfiguration.ProxyType = "socks5"
+
+	tests := []struct {
+		name    string
+		inline  string
+		proxies []ProxyConfig
+	}{
+		{
+			name:   "whitespace separated",
+			inline: "127.0.0.1:1080:u:p 127.0.0.2:1080\t127.0.0.3:1080\n# comment 127.0.0.4:1080\n127.0.0.5:1080:u:p,ss",
+			proxies: []ProxyConfig{
+				{host: "127.0.0.1", port: 1080, username: "u", password: "p", proxyType: "socks5"},
+				{host: "127.0.0.2", port: 1080, proxyType: "socks5"},
+				{host: "127.0.0.3", port: 1080, proxyType: "socks5"},
+				{host: "127.0.0.5", port: 1080, username: "u", password: "p,ss", proxyType: "socks5"},
+			},
+		},
+		{
+			name:   "CSV header with spaces",
+			inline: "host, port, username, password\n127.0.0.1, 1080, u, p\n127.0.0.2, 1081,,",
+			proxies: []ProxyConfig{
+				{host: "127.0.0.1", port: 1080, username: "u", password: "p", proxyType: "socks5", tags: []string{}},
+				{host: "127.0.0.2", port: 1081, proxyType: "socks5", tags: []string{}},
+			},
+		},
+		{
+			name:   "JSON",
+			inline: `[{"host": "127.0.0.1", "port": 1080}, "http://127.0.0.2:3128"]`,
+			proxies: []ProxyConfig{
+				{host: "127.0.0.1", port: 1080, proxyType: "socks5"},
+				{host: "127.0.0.2", port: 3128, proxyType: "http"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proxies, errs := parseProxyList(inlineProxyList(test.inline), "env")
+			if len(errs) != 0 {
+				t.Errorf("expected no errors, got %v", errs)
+			}
+
+			for i := range test.proxies {
+				test.proxies[i].source = "env"
+			}
+
+			if !reflect.DeepEqual(proxies, test.proxies) {
+				t.Errorf("expected\n%+v\ngot\n%+v", test.proxies, proxies)
+			}
+		})
+	}
+}
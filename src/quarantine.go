@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type QuarantineState int64
+
+const (
+	// proxy receives requests
+	QuarantineStateActive QuarantineState = iota
+	// proxy failed and waits for its backoff to pass
+	QuarantineStateQuarantined
+	// backoff passed and a single probe request decides whether the proxy is re-admitted
+	QuarantineStateProbing
+	// proxy failed too many times and was removed from the pool for good
+	QuarantineStateEvicted
+)
+
+func (state QuarantineState) String() string {
+	switch state {
+	case QuarantineStateActive:
+		return "active"
+	case QuarantineStateQuarantined:
+		return "quarantined"
+	case QuarantineStateProbing:
+		return "probing"
+	case QuarantineStateEvicted:
+		return "evicted"
+	default:
+		return "unknown"
+	}
+}
+
+// ProxyQuarantine keeps failing proxies out of scheduling with exponential backoff
+type ProxyQuarantine struct {
+	lock  sync.Mutex
+	state QuarantineState
+	// failures since the proxy was last active, resets after a successful probe
+	failures int
+	until    time.Time
+}
+
+func (client *ProxyClient) quarantineState() QuarantineState {
+	client.quarantine.lock.Lock()
+	defer client.quarantine.lock.Unlock()
+
+	return client.quarantine.state
+}
+
+// quarantineBackoff doubles with every failure up to QUARANTINE_MAX_BACKOFF, with ±20% jitter
+func quarantineBackoff(failures int) time.Duration {
+	backoff := float64(globalConfiguration.UnreachableClientRetry) * math.Pow(2, float64(failures-1))
+	backoff = math.Min(backoff, float64(globalConfiguration.QuarantineMaxBackoff))
+	backoff = backoff * (0.8 + rand.Float64()*0.4)
+
+	return time.Duration(backoff)
+}
+
+// setQuarantineState logs the transition, quarantine lock must be held
+func (client *ProxyClient) setQuarantineState(state QuarantineState, reason string) {
+	log.Printf("Proxy %s %s -> %s: %s", client.id(), client.quarantine.state, state, reason)
+	client.quarantine.state = state
+}
+
+// recordFailure quarantines the proxy or evicts it once it failed QUARANTINE_EVICT_AFTER times in a row
+func (client *ProxyClient) recordFailure(reason string) {
+	client.quarantine.lock.Lock()
+
+	if client.quarantine.state == QuarantineStateQuarantined || client.quarantine.state == QuarantineStateEvicted {
+		client.quarantine.lock.Unlock()
+		return
+	}
+
+	client.quarantine.failures++
+
+	evict := globalConfiguration.QuarantineEvictAfter > 0 && client.quarantine.failures >= globalConfiguration.QuarantineEvictAfter
+	if evict {
+		client.setQuarantineState(QuarantineStateEvicted, reason)
+	} else {
+		backoff := quarantineBackoff(client.quarantine.failures)
+		client.quarantine.until = time.Now().Add(backoff)
+		client.setQuarantineState(QuarantineStateQuarantined, fmt.Sprintf("%s, failure %d, retry in %s", reason, client.quarantine.failures, backoff.Round(time.Second)))
+	}
+
+	client.quarantine.lock.Unlock()
+
+	if evict {
+		proxyPool.evict(client)
+	} else {
+		proxyPool.lock.Lock()
+		proxyPool.publish()
+		proxyPool.lock.Unlock()
+	}
+}
+
+func (client *ProxyClient) markUnreachable() {
+	client.recordFailure("proxy unreachable")
+}
+
+// probeQuarantined requests the health check URL and re-admits the proxy when it succeeds
+func (client *ProxyClient) probeQuarantined(ctx context.Context, uri url.URL) {
+	resp, err := client.probe(ctx, &uri, globalConfiguration.HealthCheckTimeout)
+	if ctx.Err() != nil {
+		return
+	}
+
+	ok := err == nil && resp.Status == ResponseStatusOk && resp.Code >= 200 && resp.Code < 400
+	if !ok {
+		client.recordFailure("probe failed")
+		return
+	}
+
+	// the session of a gateway client may have changed its exit IP, or never learned it after a rotation
+	if client.gateway.Load() != nil {
+		ip, err := getExternalProxyIp(client, ctx)
+		if err != nil {
+			client.recordFailure("exit IP unknown")
+			return
+		}
+
+		if !proxyPool.changeExitIp(client, *ip) {
+			go client.rotateGatewaySession(ctx, "exit IP used by another proxy")
+		}
+	}
+
+	client.quarantine.lock.Lock()
+	client.quarantine.failures = 0
+	client.setQuarantineState(QuarantineStateActive, "probe succeeded")
+	client.quarantine.lock.Unlock()
+
+	proxyPool.lock.Lock()
+	proxyPool.publish()
+	proxyPool.lock.Unlock()
+}
+
+// runQuarantineManager moves proxies whose backoff passed to the probing state and probes them
+func runQuarantineManager(ctx context.Context) {
+	uri, err := url.Parse(globalConfiguration.HealthCheckUrl)
+	if err != nil || uri.Host == "" {
+		log.Fatalf("Invalid HEALTH_CHECK_URL %s", globalConfiguration.HealthCheckUrl)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		for _, client := range proxyPool.all() {
+			client.quarantine.lock.Lock()
+			due := client.quarantine.state == QuarantineStateQuarantined && now.After(client.quarantine.until)
+			if due {
+				client.setQuarantineState(QuarantineStateProbing, "backoff passed")
+			}
+			client.quarantine.lock.Unlock()
+
+			if due {
+				go client.probeQuarantined(ctx, *uri)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuarantineBackoff(t *testing.T) {
+	globalConfiguration.UnreachableClientRetry = time.Minute
+	globalConfiguration.QuarantineMaxBackoff = 30 * time.Minute
+
+	tests := []struct {
+		failures int
+		backoff  time.Duration
+	}{
+		{failures: 1, backoff: time.Minute},
+		{failures: 2, backoff: 2 * time.Minute},
+		{failures: 4, backoff: 8 * time.Minute},
+		{failures: 10, backoff: 30 * time.Minute},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			backoff := quarantineBackoff(test.failures)
+			if backoff < test.backoff*8/10 || backoff > test.backoff*12/10 {
+				t.Errorf("expected backoff of %d failures within 20%% of %s, got %s", test.failures, test.backoff, backoff)
+				break
+			}
+		}
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	globalConfiguration.UnreachableClientRetry = time.Minute
+	globalConfiguration.QuarantineMaxBackoff = 30 * time.Minute
+
+	tests := []struct {
+		name       string
+		evictAfter int
+		// starting state of the proxy, failures are recorded after the quarantine manager moved it to probing
+		state    QuarantineState
+		failures int
+		record   int
+		expected QuarantineState
+	}{
+		{
+			name:       "first failure quarantines",
+			evictAfter: 3,
+			state:      QuarantineStateActive,
+			record:     1,
+			expected:   QuarantineStateQuarantined,
+		},
+		{
+			name:       "failed probe quarantines again",
+			evictAfter: 3,
+			state:      QuarantineStateProbing,
+			failures:   1,
+			record:     1,
+			expected:   QuarantineStateQuarantined,
+		},
+		{
+			name:       "failures in a row evict",
+			evictAfter: 3,
+			state:      QuarantineStateActive,
+			record:     3,
+			expected:   QuarantineStateEvicted,
+		},
+		{
+			name:       "eviction disabled",
+			evictAfter: 0,
+			state:      QuarantineStateActive,
+			record:     20,
+			expected:   QuarantineStateQuarantined,
+		},
+		{
+			name:       "quarantined proxy ignores failures until probed",
+			evictAfter: 3,
+			state:      QuarantineStateQuarantined,
+			failures:   2,
+			record:     1,
+			expected:   QuarantineStateQuarantined,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			globalConfiguration.QuarantineEvictAfter = test.evictAfter
+
+			client := &ProxyClient{}
+			client.setId("192.0.2.1")
+			client.quarantine.state = test.state
+			client.quarantine.failures = test.failures
+
+			for i := 0; i < test.record; i++ {
+				if i > 0 && client.quarantineState() == QuarantineStateQuarantined {
+					client.quarantine.state = QuarantineStateProbing
+				}
+
+				client.recordFailure("test")
+			}
+
+			if state := client.quarantineState(); state != test.expected {
+				t.Errorf("expected state %s, got %s", test.expected, state)
+			}
+
+			if test.state == QuarantineStateQuarantined && client.quarantine.failures != test.failures {
+				t.Errorf("expected failures of a quarantined proxy to stay %d, got %d", test.failures, client.quarantine.failures)
+			}
+
+			if test.expected == QuarantineStateQuarantined && test.state != QuarantineStateQuarantined && client.quarantine.until.Before(time.Now()) {
+				t.Errorf("expected a backoff, got %s", client.quarantine.until)
+			}
+		})
+	}
+}
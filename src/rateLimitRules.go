@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/throttled/throttled"
+)
+
+// RateLimitRule sets how often a single exit IP may send requests to hosts matching one of its patterns
+type RateLimitRule struct {
+	Hosts []string `json:"hosts"`
+	// requests per hour are meant for very sensitive hosts, they take precedence over requests per minute
+	RequestsPerMin  int `json:"requestsPerMin"`
+	RequestsPerHour int `json:"requestsPerHour"`
+	// defaults to THROTTLE_REQUESTS_BURST
+	Burst *int `json:"burst"`
+	// unlimited only lifts the per exit IP limit, a global limit still applies
+	Unlimited bool `json:"unlimited"`
+	// limit of the whole pool to each matching host, defaults to GLOBAL_THROTTLE_REQUESTS_PER_MIN
+	GlobalRequestsPerMin  int  `json:"globalRequestsPerMin"`
+	GlobalRequestsPerHour int  `json:"globalRequestsPerHour"`
+	GlobalBurst           *int `json:"globalBurst"`
+	// exempts matching hosts from GLOBAL_THROTTLE_REQUESTS_PER_MIN
+	GlobalUnlimited bool `json:"globalUnlimited"`
+
+	patterns []hostPattern
+}
+
+// RateLimitRules is one version of the rules file, the first matching rule wins
+type RateLimitRules struct {
+	rules       []*RateLimitRule
+	defaultRule *RateLimitRule
+}
+
+var rateLimitRules atomic.Pointer[RateLimitRules]
+
+// defaultRateLimitRule applies THROTTLE_REQUESTS_PER_MIN and THROTTLE_REQUESTS_BURST to hosts without a rule
+func defaultRateLimitRule() *RateLimitRule {
+	return &RateLimitRule{RequestsPerMin: globalConfiguration.ThrottleRequestsPerMin}
+}
+
+func (rule *RateLimitRule) quota() throttled.RateQuota {
+	var rate throttled.Rate
+	if rule.RequestsPerHour > 0 {
+		rate = throttled.PerHour(rule.RequestsPerHour)
+	} else {
+		rate = throttled.PerMin(rule.RequestsPerMin)
+	}
+
+	burst := globalConfiguration.ThrottleRequestsBurst
+	if rule.Burst != nil {
+		burst = *rule.Burst
+	}
+
+	return throttled.RateQuota{MaxRate: rate, MaxBurst: burst}
+}
+
+// globalQuota returns the limit of the whole pool to hosts of the rule and its description, false when there is none
+func (rule *RateLimitRule) globalQuota() (throttled.RateQuota, string, bool) {
+	if rule.GlobalUnlimited {
+		return throttled.RateQuota{}, "", false
+	}
+
+	burst := globalConfiguration.GlobalThrottleRequestsBurst
+	if rule.GlobalBurst != nil {
+		burst = *rule.GlobalBurst
+	}
+
+	var rate throttled.Rate
+	var description string
+	if rule.GlobalRequestsPerHour > 0 {
+		rate = throttled.PerHour(rule.GlobalRequestsPerHour)
+		description = fmt.Sprintf("%d/hour, burst %d", rule.GlobalRequestsPerHour, burst)
+	} else if rule.GlobalRequestsPerMin > 0 {
+		rate = throttled.PerMin(rule.GlobalRequestsPerMin)
+		description = fmt.Sprintf("%d/min, burst %d", rule.GlobalRequestsPerMin, burst)
+	} else if globalConfiguration.GlobalThrottleRequestsPerMin > 0 {
+		rate = throttled.PerMin(globalConfiguration.GlobalThrottleRequestsPerMin)
+		description = fmt.Sprintf("%d/min, burst %d", globalConfiguration.GlobalThrottleRequestsPerMin, burst)
+	} else {
+		return throttled.RateQuota{}, "", false
+	}
+
+	return throttled.RateQuota{MaxRate: rate, MaxBurst: burst}, description, true
+}
+
+// quotaKey identifies the quota of the rule, rules with the same quota share limiters
+func (rule *RateLimitRule) quotaKey() string {
+	if rule.Unlimited {
+		return "unlimited"
+	}
+
+	quota := rule.quota()
+
+	return fmt.Sprintf("%v/%d", quota.MaxRate, quota.MaxBurst)
+}
+
+func parseRateLimitRules(content []byte) ([]*RateLimitRule, error) {
+	rules := make([]*RateLimitRule, 0)
+	err := json.Unmarshal(content, &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, rule := range rules {
+		if len(rule.Hosts) == 0 {
+			return nil, fmt.Errorf("rule %d has no hosts", i)
+		}
+
+		if !rule.Unlimited && rule.RequestsPerMin <= 0 && rule.RequestsPerHour <= 0 {
+			return nil, fmt.Errorf("rule %d needs requestsPerMin, requestsPerHour or unlimited", i)
+		}
+
+		if (rule.Burst != nil && *rule.Burst < 0) || (rule.GlobalBurst != nil && *rule.GlobalBurst < 0) {
+			return nil, fmt.Errorf("rule %d has a negative burst", i)
+		}
+
+		if rule.GlobalRequestsPerMin < 0 || rule.GlobalRequestsPerHour < 0 {
+			return nil, fmt.Errorf("rule %d has a negative global limit", i)
+		}
+
+		if rule.GlobalUnlimited && (rule.GlobalRequestsPerMin > 0 || rule.GlobalRequestsPerHour > 0) {
+			return nil, fmt.Errorf("rule %d sets both globalUnlimited and a global limit", i)
+		}
+
+		for _, host := range rule.Hosts {
+			pattern, err := parseHostPattern(host)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+
+			rule.patterns = append(rule.patterns, pattern)
+		}
+	}
+
+	return rules, nil
+}
+
+func (rules *RateLimitRules) find(host string) *RateLimitRule {
+	for _, rule := range rules.rules {
+		for _, pattern := range rule.patterns {
+			if pattern.matches(host) {
+				return rule
+			}
+		}
+	}
+
+	return rules.defaultRule
+}
+
+// rateLimitRuleFor returns the rule applying to the host, rule lists are short enough to be matched on every call
+func rateLimitRuleFor(host string) *RateLimitRule {
+	rules := rateLimitRules.Load()
+	if rules == nil {
+		return defaultRateLimitRule()
+	}
+
+	return rules.find(host)
+}
+
+// loadRateLimitRules reads RATE_LIMIT_RULES_FILE, without it every host uses the THROTTLE_* quota
+func loadRateLimitRules() error {
+	rules := make([]*RateLimitRule, 0)
+
+	if globalConfiguration.RateLimitRulesFile != "" {
+		content, err := os.ReadFile(globalConfiguration.RateLimitRulesFile)
+		if err != nil {
+			return err
+		}
+
+		rules, err = parseRateLimitRules(content)
+		if err != nil {
+			return fmt.Errorf("invalid rate limit rules %s: %w", globalConfiguration.RateLimitRulesFile, err)
+		}
+
+		log.Printf("Loaded %d rate limit rules from %s", len(rules), globalConfiguration.RateLimitRulesFile)
+	}
+
+	rateLimitRules.Store(&RateLimitRules{rules: rules, defaultRule: defaultRateLimitRule()})
+
+	return nil
+}
+
+// runRateLimitRulesWatcher reloads RATE_LIMIT_RULES_FILE when it changes, a file that fails to load keeps the previous rules
+func runRateLimitRulesWatcher(ctx context.Context) {
+	if globalConfiguration.RateLimitRulesFile == "" {
+		return
+	}
+
+	lastModTime := time.Time{}
+	if info, err := os.Stat(globalConfiguration.RateLimitRulesFile); err == nil {
+		lastModTime = info.ModTime()
+	}
+
+	ticker := time.NewTicker(globalConfiguration.RateLimitRulesPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(globalConfiguration.RateLimitRulesFile)
+		if err != nil || info.ModTime().Equal(lastModTime) {
+			continue
+		}
+
+		lastModTime = info.ModTime()
+
+		err = loadRateLimitRules()
+		if err != nil {
+			log.Printf("Error reloading rate limit rules, keeping the previous ones: %v", err)
+		}
+	}
+}
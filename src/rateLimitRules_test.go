@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRateLimitRuleFor(t *testing.T) {
+	globalConfiguration.ThrottleRequestsPerMin = 30
+
+	rules, err := parseRateLimitRules([]byte(`[
+		{"hosts": ["login.example.com"], "requestsPerHour": 20},
+		{"hosts": ["*.example.com", "example.org"], "requestsPerMin": 60},
+		{"hosts": ["/^cdn[0-9]+\\.example\\.net$/"], "unlimited": true}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rateLimitRules.Store(&RateLimitRules{rules: rules, defaultRule: defaultRateLimitRule()})
+	defer rateLimitRules.Store(nil)
+
+	tests := []struct {
+		host string
+		// index of the matching rule, -1 for the default rule
+		rule int
+	}{
+		{host: "login.example.com", rule: 0},
+		{host: "LOGIN.example.com", rule: 0},
+		{host: "www.example.com", rule: 1},
+		{host: "example.com", rule: -1},
+		{host: "example.org", rule: 1},
+		{host: "www.example.org", rule: -1},
+		{host: "cdn12.example.net", rule: 2},
+		{host: "cdn.example.net", rule: -1},
+		{host: "unrelated.test", rule: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.host, func(t *testing.T) {
+			rule := rateLimitRuleFor(test.host)
+
+			if test.rule < 0 {
+				if rule.RequestsPerMin != 30 || len(rule.Hosts) != 0 {
+					t.Errorf("expected the default rule, got %+v", rule)
+				}
+			} else if rule != rules[test.rule] {
+				t.Errorf("expected rule %d, got %+v", test.rule, rule)
+			}
+		})
+	}
+}
+
+func TestParseRateLimitRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		valid   bool
+	}{
+		{name: "per minute", content: `[{"hosts": ["example.com"], "requestsPerMin": 10}]`, valid: true},
+		{name: "global unlimited", content: `[{"hosts": ["*"], "unlimited": true, "globalUnlimited": true}]`, valid: true},
+		{name: "no hosts", content: `[{"requestsPerMin": 10}]`},
+		{name: "no rate", content: `[{"hosts": ["example.com"]}]`},
+		{name: "negative burst", content: `[{"hosts": ["example.com"], "requestsPerMin": 10, "burst": -1}]`},
+		{name: "negative global limit", content: `[{"hosts": ["example.com"], "requestsPerMin": 10, "globalRequestsPerMin": -1}]`},
+		{name: "global unlimited with a global limit", content: `[{"hosts": ["example.com"], "requestsPerMin": 10, "globalUnlimited": true, "globalRequestsPerMin": 5}]`},
+		{name: "wildcard inside a host", content: `[{"hosts": ["www.*.com"], "requestsPerMin": 10}]`},
+		{name: "invalid regex", content: `[{"hosts": ["/(/"], "requestsPerMin": 10}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseRateLimitRules([]byte(test.content))
+			if (err == nil) != test.valid {
+				t.Errorf("expected valid %v, got %v", test.valid, err)
+			}
+		})
+	}
+}
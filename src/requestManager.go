@@ -3,11 +3,15 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dustin/go-broadcast"
@@ -38,33 +42,107 @@ type Response struct {
 	Code    int
 	Body    []byte
 	Headers http.Header
+	// set instead of Body for streamed requests, must be closed by the receiver
+	BodyStream io.ReadCloser
+	// the session of the request was moved to another proxy because the pinned one became unavailable
+	SessionChanged bool
+}
+
+// discard closes the body stream of a response that is not going to be delivered
+func (resp *Response) discard() {
+	if resp != nil && resp.BodyStream != nil {
+		err := resp.BodyStream.Close()
+		if err != nil {
+			log.Printf("Error closing discarded body stream: %v", err)
+		}
+	}
 }
 
 type ActiveRequest struct {
-	Id           uint64
+	Id              uint64
+	Url             *url.URL
+	Method          string
+	Headers         http.Header
+	Body            []byte
+	Priority        int64
+	Host            HostInfo
+	Status          RequestStatus
+	Retries         uint32
+	Context         context.Context
+	Callback        chan<- *Response
+	Lock            sync.Mutex
+	RetryOnCodes    []uint16
+	Stream          bool
+	ProxyTags       []string
+	PreferProxyTags bool
+	// ISO country codes the proxy has to exit in
+	ProxyCountries []string
+	// requests of the same session are executed at the same proxy
+	SessionId      string
+	SessionChanged bool
+	// cookies are neither sent from nor stored to the cookie jar of the proxy
+	DisableCookieJar bool
+	// escalation policy of the target host and the tier of proxies the request is currently sent through
+	Policy *EscalationPolicy
+	Tier   int
+	// the request had to wait for the global limit of its host
+	GloballyLimited bool
+}
+
+// RequestOptions describes a request as submitted by one of the proxy interfaces
+type RequestOptions struct {
 	Url          *url.URL
 	Method       string
+	Headers      http.Header
+	Body         []byte
 	Priority     int64
-	Host         HostInfo
-	Status       RequestStatus
-	Retries      uint32
-	Context      context.Context
-	Callback     chan<- *Response
-	Lock         sync.Mutex
 	RetryOnCodes []uint16
+	Stream       bool
+	// only proxies having all of these tags are used, unless PreferProxyTags allows falling back to others
+	ProxyTags        []string
+	PreferProxyTags  bool
+	ProxyCountries   []string
+	SessionId        string
+	DisableCookieJar bool
 }
 
 var requestCounter uint64 = 0
 var newRequestsBroacast = broadcast.NewBroadcaster(1)
 var requestFinishedBroacast = broadcast.NewBroadcaster(1)
 
-func initializeRequest(
-	uri *url.URL,
-	priority int64,
-	retryOnCodes []uint16,
-	ctx context.Context,
-) (*ActiveRequest, <-chan *Response, error) {
-	hostInfo := getHostInfo(uri.Hostname())
+var errInvalidMethod = errors.New("unsupported request method")
+
+var allowedMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodOptions: {},
+}
+
+// normalizeMethod upper-cases the method, defaults it to GET and rejects methods we are not able to proxy
+func normalizeMethod(method string) (string, error) {
+	if method == "" {
+		return http.MethodGet, nil
+	}
+
+	method = strings.ToUpper(method)
+	if _, ok := allowedMethods[method]; !ok {
+		return "", errInvalidMethod
+	}
+
+	return method, nil
+}
+
+func initializeRequest(opts RequestOptions, ctx context.Context) (*ActiveRequest, <-chan *Response, error) {
+	method, err := normalizeMethod(opts.Method)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hostInfo := getHostInfo(opts.Url.Hostname())
 	if !hostInfo.isOnline() {
 		return nil, nil, errors.New("host is not reachable")
 	}
@@ -72,17 +150,31 @@ func initializeRequest(
 	requestCounter = requestCounter + 1
 	callback := make(chan *Response, 1)
 
+	headers := opts.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+
 	req := &ActiveRequest{
-		Id:           requestCounter - 1,
-		Url:          uri,
-		Method:       "GET",
-		Priority:     priority,
-		Host:         *hostInfo,
-		Status:       RequestStatus(RequestStatusPending),
-		Retries:      0,
-		Callback:     callback,
-		Context:      ctx,
-		RetryOnCodes: retryOnCodes,
+		Id:               requestCounter - 1,
+		Url:              opts.Url,
+		Method:           method,
+		Headers:          headers,
+		Body:             opts.Body,
+		Priority:         opts.Priority,
+		Host:             *hostInfo,
+		Status:           RequestStatus(RequestStatusPending),
+		Retries:          0,
+		Callback:         callback,
+		Context:          ctx,
+		RetryOnCodes:     opts.RetryOnCodes,
+		Stream:           opts.Stream,
+		ProxyTags:        opts.ProxyTags,
+		PreferProxyTags:  opts.PreferProxyTags,
+		ProxyCountries:   opts.ProxyCountries,
+		SessionId:        opts.SessionId,
+		DisableCookieJar: opts.DisableCookieJar,
+		Policy:           findEscalationPolicy(opts.Url.Hostname()),
 	}
 
 	newRequestsBroacast.Submit(req)
@@ -95,7 +187,18 @@ func (request *ActiveRequest) executeAt(proxy *ProxyClient) {
 	request.Status = RequestStatus(RequestStatusActive)
 	request.Lock.Unlock()
 
+	start := time.Now()
 	resp, err := proxy.makeRequestWithClient(request, globalConfiguration.RequestTimeout)
+	proxy.stats.record(resp, err, time.Since(start))
+
+	// a streamed body keeps the proxy busy until it is fully passed to the client
+	if err == nil && resp.BodyStream != nil {
+		resp.BodyStream = newStreamBody(resp.BodyStream, func(int64) {
+			proxy.release()
+		})
+	} else {
+		proxy.release()
+	}
 
 	request.Lock.Lock()
 	defer request.Lock.Unlock()
@@ -136,9 +239,12 @@ func (request *ActiveRequest) executeAt(proxy *ProxyClient) {
 
 			return
 		} else if resp.Code == 429 {
-			_, _, err = proxy.limiter.RateLimit(request.Host.host, 100)
+			if limiter := proxy.exitIp().limiterFor(request.Host.host); limiter != nil {
+				_, _, err = limiter.RateLimit(request.Host.host, 100)
+			}
 			if err != nil {
 				log.Printf("UNKNOWN ERROR in rateLimiter %s: %v", request.Url, err)
+				resp.discard()
 
 				request.Callback <- &Response{
 					Status: ResponseStatusUnknownError,
@@ -158,13 +264,27 @@ func (request *ActiveRequest) executeAt(proxy *ProxyClient) {
 		}
 	}
 
+	if proxy.rotatesOn(request, resp) {
+		go proxy.rotateGatewaySession(context.Background(), fmt.Sprintf("%s returned %d", request.Url.Hostname(), resp.Code))
+	}
+
+	blocked := request.Policy != nil && request.Policy.isBlocked(resp)
+	if blocked {
+		retry = true
+	}
+
 	if retry && int(request.Retries) < globalConfiguration.Retries {
+		resp.discard()
+		if blocked {
+			request.escalate()
+		}
 		request.Retries = request.Retries + 1
 		request.Status = RequestStatus(RequestStatusPending)
 
 		return
 	}
 
+	resp.SessionChanged = request.SessionChanged
 	request.Callback <- resp
 }
 
@@ -210,6 +330,8 @@ func runRequestScheduler(ctx context.Context) {
 		case newProxies := <-proxyListChanged:
 			proxies = newProxies.([]*ProxyClient)
 			retryRequestAfter = 0
+		case <-proxyReleased:
+			retryRequestAfter = 0
 		case <-time.After(retryRequestAfter):
 		}
 
@@ -225,8 +347,34 @@ func runRequestScheduler(ctx context.Context) {
 				return true
 			}
 
-			for _, idx := range shuffleIndices(len(proxies)) {
-				proxy := proxies[idx]
+			// checked before any proxy so that proxy tokens are not used up while the whole pool has to wait
+			global := globalLimits.limit(item.Host.host)
+			if global != nil {
+				limited, result, err := global.peek()
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				if limited {
+					if !item.GloballyLimited {
+						item.GloballyLimited = true
+						atomic.AddInt64(&global.delayed, 1)
+					}
+
+					if result.RetryAfter < retryRequestAfter {
+						retryRequestAfter = result.RetryAfter
+					}
+
+					return true
+				}
+			}
+
+			for _, proxy := range item.candidateProxies(proxies) {
+				// checked before the rate limit so that a busy proxy does not use up tokens
+				if proxy.atCapacity() {
+					continue
+				}
+
 				limited, result, err := proxy.RateLimit(item.Host)
 				if err != nil {
 					log.Fatal(err)
@@ -240,7 +388,17 @@ func runRequestScheduler(ctx context.Context) {
 					continue
 				}
 
+				if global != nil {
+					err = global.take()
+					if err != nil {
+						log.Fatal(err)
+					}
+				}
+
 				pq.Delete(item)
+				item.pinSession(proxy)
+				// counted here rather than in executeAt so that the next requests of this round see it
+				atomic.AddInt64(&proxy.inFlight, 1)
 				go item.executeAt(proxy)
 				retryRequestAfter = 0
 				return false
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+
+	"github.com/ReneKroon/ttlcache"
+)
+
+// proxies sessions are pinned to by session id, the TTL is extended with every request of the session
+var sessionCache = ttlcache.NewCache()
+
+// SessionPin is the proxy a session is pinned to and the exit IP it had, a gateway rotation changes the IP of the same proxy
+type SessionPin struct {
+	proxy  *ProxyClient
+	exitIp string
+}
+
+// pinnedProxy returns the proxy the session of the request is pinned to if it is still available for scheduling and exits through the same IP
+func (request *ActiveRequest) pinnedProxy(proxies []*ProxyClient) *ProxyClient {
+	if request.SessionId == "" {
+		return nil
+	}
+
+	value, ok := sessionCache.Get(request.SessionId)
+	if !ok {
+		return nil
+	}
+
+	pinned := value.(SessionPin)
+	if pinned.proxy.exitIp().ip != pinned.exitIp {
+		return nil
+	}
+
+	for _, proxy := range proxies {
+		if proxy == pinned.proxy {
+			return proxy
+		}
+	}
+
+	return nil
+}
+
+// pinSession pins the session of the request to the proxy it is about to be executed at, noting when the session moves to another proxy
+// only the request scheduler goroutine pins sessions, so pinnedProxy and pinSession need no lock
+func (request *ActiveRequest) pinSession(proxy *ProxyClient) {
+	if request.SessionId == "" {
+		return
+	}
+
+	pin := SessionPin{proxy: proxy, exitIp: proxy.exitIp().ip}
+
+	value, ok := sessionCache.Get(request.SessionId)
+	if ok && value.(SessionPin) != pin {
+		log.Printf("Session %s moved from proxy %s to %s", request.SessionId, value.(SessionPin).exitIp, pin.exitIp)
+		request.SessionChanged = true
+	}
+
+	sessionCache.SetWithTTL(request.SessionId, pin, globalConfiguration.SessionTtl)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionPinning(t *testing.T) {
+	globalConfiguration.SessionTtl = time.Minute
+
+	newProxy := func(ip string) *ProxyClient {
+		client := &ProxyClient{}
+		client.setId(ip)
+		client.exit.Store(&ExitIp{ip: ip})
+
+		return client
+	}
+
+	first := newProxy("192.0.2.1")
+	second := newProxy("192.0.2.2")
+	rotated := newProxy("192.0.2.3")
+
+	tests := []struct {
+		name      string
+		sessionId string
+		// proxy the session was pinned to before, if any
+		pinnedTo *ProxyClient
+		// runs between pinning and looking the pin up
+		change  func()
+		proxies []*ProxyClient
+		pinned  *ProxyClient
+		// proxy the request is executed at and whether that changes the session
+		executeAt *ProxyClient
+		changed   bool
+	}{
+		{
+			name:      "no session",
+			proxies:   []*ProxyClient{first, second},
+			executeAt: first,
+		},
+		{
+			name:      "new session",
+			sessionId: "new",
+			proxies:   []*ProxyClient{first, second},
+			executeAt: first,
+		},
+		{
+			name:      "pinned proxy available",
+			sessionId: "available",
+			pinnedTo:  second,
+			proxies:   []*ProxyClient{first, second},
+			pinned:    second,
+			executeAt: second,
+		},
+		{
+			name:      "pinned proxy left the pool",
+			sessionId: "left",
+			pinnedTo:  second,
+			proxies:   []*ProxyClient{first},
+			executeAt: first,
+			changed:   true,
+		},
+		{
+			name:      "pinned proxy rotated its exit IP",
+			sessionId: "rotated",
+			pinnedTo:  rotated,
+			change: func() {
+				rotated.exit.Store(&ExitIp{ip: "192.0.2.4"})
+			},
+			proxies:   []*ProxyClient{first, rotated},
+			executeAt: rotated,
+			changed:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sessionCache.Remove(test.sessionId)
+
+			if test.pinnedTo != nil {
+				(&ActiveRequest{SessionId: test.sessionId}).pinSession(test.pinnedTo)
+			}
+
+			if test.change != nil {
+				test.change()
+			}
+
+			request := &ActiveRequest{SessionId: test.sessionId}
+
+			pinned := request.pinnedProxy(test.proxies)
+			if pinned != test.pinned {
+				t.Errorf("expected pinned proxy %v, got %v", test.pinned, pinned)
+			}
+
+			request.pinSession(test.executeAt)
+			if request.SessionChanged != test.changed {
+				t.Errorf("expected session changed %v, got %v", test.changed, request.SessionChanged)
+			}
+
+			if test.sessionId != "" && request.pinnedProxy(test.proxies) != test.executeAt {
+				t.Errorf("expected the session to be pinned to the proxy it was executed at")
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// PersistedState is the content of STATE_FILE
+type PersistedState struct {
+	SavedAt time.Time                 `json:"savedAt"`
+	Proxies map[string]PersistedProxy `json:"proxies"`
+	// when proxies were evicted by quarantine by ProxyConfig.key
+	Evicted map[string]time.Time `json:"evictedAt"`
+	// rate limiter state by exit IP
+	Limiters map[string]map[string]PersistedLimiterEntry `json:"limiters"`
+	// global rate limiter state by target host
+	GlobalLimiters map[string]PersistedLimiterEntry `json:"globalLimiters"`
+	Hosts          map[string]PersistedHost         `json:"hosts"`
+	// bandwidth usage by source and by target host
+	SourceBandwidth map[string]PersistedBandwidth `json:"sourceBandwidth"`
+	HostBandwidth   map[string]PersistedBandwidth `json:"hostBandwidth"`
+}
+
+type PersistedProxy struct {
+	ExitIp string `json:"exitIp"`
+	// last time the exit IP was confirmed, by connecting or by a passed health check
+	ValidatedAt        time.Time          `json:"validatedAt"`
+	Healthy            bool               `json:"healthy"`
+	QuarantineState    QuarantineState    `json:"quarantineState"`
+	QuarantineFailures int                `json:"quarantineFailures"`
+	QuarantinedUntil   time.Time          `json:"quarantinedUntil"`
+	GatewaySession     string             `json:"gatewaySession,omitempty"`
+	Bandwidth          PersistedBandwidth `json:"bandwidth"`
+}
+
+type PersistedLimiterEntry struct {
+	Value     int64     `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type PersistedHost struct {
+	SupportsHttp  bool      `json:"supportsHttp"`
+	SupportsHttps bool      `json:"supportsHttps"`
+	SupportsH2    bool      `json:"supportsH2"`
+	SupportsIPv4  bool      `json:"supportsIPv4"`
+	SupportsIPv6  bool      `json:"supportsIPv6"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+type PersistedBandwidth struct {
+	Requests   int64  `json:"requests"`
+	Failed     int64  `json:"failed"`
+	In         int64  `json:"in"`
+	Out        int64  `json:"out"`
+	Day        string `json:"day"`
+	DayBytes   int64  `json:"dayBytes"`
+	Month      string `json:"month"`
+	MonthBytes int64  `json:"monthBytes"`
+}
+
+// state loaded on startup, parts of it are consumed as proxies connect
+var restoredState = struct {
+	lock  sync.Mutex
+	state *PersistedState
+}{}
+
+// loadState restores state saved by a previous run from STATE_FILE, a missing file is not an error
+func loadState() error {
+	if globalConfiguration.StateFile == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(globalConfiguration.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	state := &PersistedState{}
+	err = json.Unmarshal(content, state)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	for host, persisted := range state.Hosts {
+		if now.Before(persisted.ExpiresAt) {
+			setHostInfo(&HostInfo{
+				host:          host,
+				supportsHttp:  persisted.SupportsHttp,
+				supportsHttps: persisted.SupportsHttps,
+				supportsH2:    persisted.SupportsH2,
+				supportsIPv4:  persisted.SupportsIPv4,
+				supportsIPv6:  persisted.SupportsIPv6,
+			}, persisted.ExpiresAt.Sub(now))
+		}
+	}
+
+	globalLimits.store.lock.Lock()
+	for key, entry := range state.GlobalLimiters {
+		globalLimits.store.entries[key] = limiterEntry{value: entry.Value, expiresAt: entry.ExpiresAt}
+	}
+	globalLimits.store.lock.Unlock()
+
+	proxyPool.lock.Lock()
+	// evictions used to last until restart, the state file keeps them for STATE_EVICTED_MAX_AGE only
+	for key, evictedAt := range state.Evicted {
+		if now.Sub(evictedAt) < globalConfiguration.StateEvictedMaxAge {
+			proxyPool.evicted[key] = evictedAt
+		}
+	}
+	proxyPool.lock.Unlock()
+
+	for name, persisted := range state.SourceBandwidth {
+		bandwidth.source(name).restore(persisted)
+	}
+
+	for name, persisted := range state.HostBandwidth {
+		bandwidth.host(name).restore(persisted)
+	}
+
+	restoredState.lock.Lock()
+	restoredState.state = state
+	restoredState.lock.Unlock()
+
+	log.Printf("Restored state saved at %s with %d proxies and %d hosts", state.SavedAt.Format(time.RFC3339), len(state.Proxies), len(state.Hosts))
+
+	return nil
+}
+
+// restoredProxy returns the saved state of the proxy, it is handed out only once
+func restoredProxy(key string) (PersistedProxy, bool) {
+	restoredState.lock.Lock()
+	defer restoredState.lock.Unlock()
+
+	if restoredState.state == nil {
+		return PersistedProxy{}, false
+	}
+
+	persisted, ok := restoredState.state.Proxies[key]
+	delete(restoredState.state.Proxies, key)
+
+	return persisted, ok
+}
+
+// restoredLimiterStore returns a limiter store with the saved limiter state of the exit IP
+func restoredLimiterStore(ip string) *LimiterStore {
+	store := newLimiterStore()
+
+	restoredState.lock.Lock()
+	defer restoredState.lock.Unlock()
+
+	if restoredState.state == nil {
+		return store
+	}
+
+	for key, entry := range restoredState.state.Limiters[ip] {
+		store.entries[key] = limiterEntry{value: entry.Value, expiresAt: entry.ExpiresAt}
+	}
+	delete(restoredState.state.Limiters, ip)
+
+	return store
+}
+
+// isFresh reports whether the exit IP was confirmed recently enough to skip detecting it again
+func (persisted PersistedProxy) isFresh() bool {
+	return persisted.ExitIp != "" && persisted.Healthy && time.Since(persisted.ValidatedAt) < globalConfiguration.StateMaxAge
+}
+
+// restore applies saved quarantine and bandwidth of the proxy, health is checked again
+func (client *ProxyClient) restore(persisted PersistedProxy) {
+	client.quarantine.lock.Lock()
+	client.quarantine.state = persisted.QuarantineState
+	client.quarantine.failures = persisted.QuarantineFailures
+	client.quarantine.until = persisted.QuarantinedUntil
+	// a probe interrupted by the restart is repeated
+	if client.quarantine.state == QuarantineStateProbing {
+		client.quarantine.state = QuarantineStateQuarantined
+	}
+	client.quarantine.lock.Unlock()
+
+	client.bandwidth.restore(persisted.Bandwidth)
+}
+
+func (usage *BandwidthUsage) restore(persisted PersistedBandwidth) {
+	usage.lock.Lock()
+	defer usage.lock.Unlock()
+
+	usage.requests += persisted.Requests
+	usage.failed += persisted.Failed
+	usage.in += persisted.In
+	usage.out += persisted.Out
+	usage.day = persisted.Day
+	usage.dayBytes += persisted.DayBytes
+	usage.month = persisted.Month
+	usage.monthBytes += persisted.MonthBytes
+	usage.roll(time.Now())
+}
+
+func (usage *BandwidthUsage) persist() PersistedBandwidth {
+	usage.lock.Lock()
+	defer usage.lock.Unlock()
+
+	return PersistedBandwidth{
+		Requests:   usage.requests,
+		Failed:     usage.failed,
+		In:         usage.in,
+		Out:        usage.out,
+		Day:        usage.day,
+		DayBytes:   usage.dayBytes,
+		Month:      usage.month,
+		MonthBytes: usage.monthBytes,
+	}
+}
+
+func (client *ProxyClient) persist() PersistedProxy {
+	persisted := PersistedProxy{
+		ExitIp:      client.exitIp().ip,
+		ValidatedAt: client.connectedAt,
+		Bandwidth:   client.bandwidth.persist(),
+	}
+
+	if session := client.gateway.Load(); session != nil {
+		persisted.GatewaySession = session.id
+	}
+
+	client.health.lock.Lock()
+	persisted.Healthy = client.health.healthy
+	if client.health.consecutiveFailures == 0 && client.health.lastCheckAt.After(persisted.ValidatedAt) {
+		persisted.ValidatedAt = client.health.lastCheckAt
+	}
+	client.health.lock.Unlock()
+
+	client.quarantine.lock.Lock()
+	persisted.QuarantineState = client.quarantine.state
+	persisted.QuarantineFailures = client.quarantine.failures
+	persisted.QuarantinedUntil = client.quarantine.until
+	client.quarantine.lock.Unlock()
+
+	return persisted
+}
+
+func (store *LimiterStore) persist() map[string]PersistedLimiterEntry {
+	now := time.Now()
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	entries := make(map[string]PersistedLimiterEntry, len(store.entries))
+	for key, entry := range store.entries {
+		if now.Before(entry.expiresAt) {
+			entries[key] = PersistedLimiterEntry{Value: entry.value, ExpiresAt: entry.expiresAt}
+		}
+	}
+
+	return entries
+}
+
+// saveState writes the current state to STATE_FILE, replacing the previous file atomically
+func saveState() error {
+	state := PersistedState{
+		SavedAt:         time.Now(),
+		Proxies:         make(map[string]PersistedProxy),
+		Evicted:         make(map[string]time.Time),
+		Limiters:        make(map[string]map[string]PersistedLimiterEntry),
+		Hosts:           make(map[string]PersistedHost),
+		SourceBandwidth: make(map[string]PersistedBandwidth),
+		HostBandwidth:   make(map[string]PersistedBandwidth),
+	}
+
+	proxyPool.lock.Lock()
+	for key, client := range proxyPool.clients {
+		state.Proxies[key] = client.persist()
+	}
+
+	for key, evictedAt := range proxyPool.evicted {
+		state.Evicted[key] = evictedAt
+	}
+
+	for ip, group := range proxyPool.exitIps {
+		state.Limiters[ip] = group.store.persist()
+	}
+	proxyPool.lock.Unlock()
+
+	state.GlobalLimiters = globalLimits.store.persist()
+
+	for host, info := range knownHostInfos() {
+		state.Hosts[host] = PersistedHost{
+			SupportsHttp:  info.supportsHttp,
+			SupportsHttps: info.supportsHttps,
+			SupportsH2:    info.supportsH2,
+			SupportsIPv4:  info.supportsIPv4,
+			SupportsIPv6:  info.supportsIPv6,
+			ExpiresAt:     info.expiresAt,
+		}
+	}
+
+	bandwidth.lock.Lock()
+	sources := make(map[string]*BandwidthUsage, len(bandwidth.sources))
+	for name, usage := range bandwidth.sources {
+		sources[name] = usage
+	}
+	hosts := make(map[string]*BandwidthUsage, len(bandwidth.hosts))
+	for name, usage := range bandwidth.hosts {
+		hosts[name] = usage
+	}
+	bandwidth.lock.Unlock()
+
+	for name, usage := range sources {
+		state.SourceBandwidth[name] = usage.persist()
+	}
+
+	for name, usage := range hosts {
+		state.HostBandwidth[name] = usage.persist()
+	}
+
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := globalConfiguration.StateFile + ".tmp"
+	err = os.WriteFile(tmpFile, content, 0o600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, globalConfiguration.StateFile)
+}
+
+// runStateSaver saves the state every STATE_SAVE_INTERVAL
+func runStateSaver(ctx context.Context) {
+	if globalConfiguration.StateFile == "" {
+		return
+	}
+
+	ticker := time.NewTicker(globalConfiguration.StateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := saveState()
+			if err != nil {
+				log.Printf("Error saving state: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// streamBody is a response body passed through to the client while it is still being downloaded
+type streamBody struct {
+	body      io.ReadCloser
+	read      int64
+	onClose   func(read int64)
+	closeOnce sync.Once
+}
+
+func newStreamBody(body io.ReadCloser, onClose func(read int64)) *streamBody {
+	return &streamBody{
+		body:    body,
+		onClose: onClose,
+	}
+}
+
+func (stream *streamBody) Read(p []byte) (int, error) {
+	n, err := stream.body.Read(p)
+	atomic.AddInt64(&stream.read, int64(n))
+
+	return n, err
+}
+
+func (stream *streamBody) Close() error {
+	err := stream.body.Close()
+
+	stream.closeOnce.Do(func() {
+		stream.onClose(atomic.LoadInt64(&stream.read))
+	})
+
+	return err
+}
@@ -2,9 +2,14 @@ package main
 
 import (
 	_ "embed"
+	"fmt"
 	"html/template"
+	"log"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 import (
 	"context"
@@ -17,11 +22,116 @@ var templateMainString string
 //go:embed templates/pending.html
 var templatePendingString string
 
+//go:embed templates/proxies.html
+var templateProxiesString string
+
+//go:embed templates/bandwidth.html
+var templateBandwidthString string
+
+//go:embed templates/limits.html
+var templateLimitsString string
+
 type PendingTemplateData struct {
 	Items []*ActiveRequest
 	Total int
 }
 
+// ProxyView is what the dashboard shows about a single proxy
+type ProxyView struct {
+	Id           string
+	Address      string
+	Source       string
+	Tags         string
+	Country      string
+	Asn          string
+	Organization string
+	ExitIp       string
+	// number of other proxies exiting through the same IP
+	SharedWith int
+	InFlight   int64
+	// zero when unlimited
+	MaxInFlight      int64
+	Healthy          bool
+	State            string
+	QuarantinedUntil string
+	Latency          string
+	Score            string
+	SuccessRate      string
+	TimeoutRate      string
+	TooManyRequests  string
+	AvgLatency       string
+	Bandwidth        BandwidthView
+	QuotaExhausted   bool
+}
+
+type BandwidthTemplateData struct {
+	Sources    []BandwidthView
+	Hosts      []BandwidthView
+	TotalHosts int
+}
+
+type LimitsTemplateData struct {
+	Items []GlobalLimitView
+}
+
+type ProxiesTemplateData struct {
+	Items []ProxyView
+	Total int
+}
+
+func (client *ProxyClient) view() ProxyView {
+	score := client.stats.score()
+
+	exit := client.exitIp()
+	sharedWith := proxyPool.sharedWith(client)
+
+	asn := ""
+	if exit.geo.asn != 0 {
+		asn = fmt.Sprintf("AS%d", exit.geo.asn)
+	}
+
+	client.stats.lock.Lock()
+	defer client.stats.lock.Unlock()
+
+	client.health.lock.Lock()
+	defer client.health.lock.Unlock()
+
+	client.quarantine.lock.Lock()
+	defer client.quarantine.lock.Unlock()
+
+	quarantinedUntil := ""
+	if client.quarantine.state == QuarantineStateQuarantined {
+		quarantinedUntil = time.Until(client.quarantine.until).Round(time.Second).String()
+	}
+
+	bandwidthView := client.bandwidth.view(client.id())
+
+	return ProxyView{
+		Id:               client.id(),
+		Address:          client.config.address(),
+		Source:           client.config.source,
+		Tags:             strings.Join(client.tags(), ", "),
+		Country:          client.country(),
+		Asn:              asn,
+		Organization:     exit.geo.organization,
+		ExitIp:           exit.ip,
+		SharedWith:       sharedWith,
+		InFlight:         atomic.LoadInt64(&client.inFlight),
+		MaxInFlight:      client.maxInFlight(),
+		Healthy:          client.health.healthy,
+		State:            client.quarantine.state.String(),
+		QuarantinedUntil: quarantinedUntil,
+		Latency:          client.health.latency.Round(time.Millisecond).String(),
+		Score:            fmt.Sprintf("%.2f", score),
+		SuccessRate:      fmt.Sprintf("%.0f%%", client.stats.successRate*100),
+		TimeoutRate:      fmt.Sprintf("%.0f%%", client.stats.timeoutRate*100),
+		TooManyRequests:  fmt.Sprintf("%.0f%%", client.stats.tooManyRequestsRate*100),
+		AvgLatency:       fmt.Sprintf("%.0fms", client.stats.latency),
+		Bandwidth:        bandwidthView,
+		QuotaExhausted:   client.quotaExhausted.Load(),
+	}
+}
+
 func runWeb(ctx context.Context) {
 	app := fiber.New()
 
@@ -63,6 +173,21 @@ func runWeb(ctx context.Context) {
 		panic(err)
 	}
 
+	templateProxies, err := template.New("foo").Parse(templateProxiesString)
+	if err != nil {
+		panic(err)
+	}
+
+	templateBandwidth, err := template.New("foo").Parse(templateBandwidthString)
+	if err != nil {
+		panic(err)
+	}
+
+	templateLimits, err := template.New("foo").Parse(templateLimitsString)
+	if err != nil {
+		panic(err)
+	}
+
 	app.Get("/", func(c *fiber.Ctx) error {
 		c.Context().SetContentType("text/html")
 
@@ -111,6 +236,84 @@ func runWeb(ctx context.Context) {
 		return nil
 	})
 
+	app.Get("/proxies", func(c *fiber.Ctx) error {
+		clients := proxyPool.snapshot()
+
+		items := make([]ProxyView, 0, len(clients))
+		for _, client := range clients {
+			items = append(items, client.view())
+		}
+
+		c.Context().SetContentType("text/html")
+
+		data := ProxiesTemplateData{
+			Total: len(items),
+			Items: items,
+		}
+
+		err := templateProxies.Execute(c, data)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	app.Get("/bandwidth", func(c *fiber.Ctx) error {
+		hosts := bandwidth.views(bandwidth.hosts)
+
+		c.Context().SetContentType("text/html")
+
+		data := BandwidthTemplateData{
+			Sources:    bandwidth.views(bandwidth.sources),
+			Hosts:      hosts,
+			TotalHosts: len(hosts),
+		}
+
+		if len(data.Hosts) > 50 {
+			data.Hosts = data.Hosts[:50]
+		}
+
+		err := templateBandwidth.Execute(c, data)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	app.Get("/limits", func(c *fiber.Ctx) error {
+		c.Context().SetContentType("text/html")
+
+		data := LimitsTemplateData{
+			Items: globalLimits.views(),
+		}
+
+		err := templateLimits.Execute(c, data)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	app.Post("/gateways/rotate", func(c *fiber.Ctx) error {
+		rotated := proxyPool.rotateGateways(ctx, c.Query("proxy"))
+
+		return c.JSON(fiber.Map{"rotated": rotated})
+	})
+
+	app.Get("/cookies", func(c *fiber.Ctx) error {
+		return c.JSON(cookieJars.list(c.Query("proxy"), c.Query("host")))
+	})
+
+	app.Delete("/cookies", func(c *fiber.Ctx) error {
+		removed := cookieJars.clear(c.Query("proxy"), c.Query("host"))
+		log.Printf("Cleared %d cookie jars", removed)
+
+		return c.JSON(fiber.Map{"removed": removed})
+	})
+
 	err = app.Listen(":8081")
 	if err != nil {
 		panic(err)